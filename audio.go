@@ -0,0 +1,235 @@
+package elevenlabs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AudioDuration returns the playback duration of audio data generated in the given output format.
+//
+// The duration can only be derived from the size of the data for the uncompressed formats, i.e. pcm_* (16-bit
+// mono) and ulaw_8000/alaw_8000 (8-bit mono). For mp3 output, use the alignment information returned with the
+// audio instead (see StreamingAlignmentSegment.Duration).
+//
+// It returns the duration, or an error if the format is not supported.
+func AudioDuration(data []byte, format string) (time.Duration, error) {
+	codec, rate, ok := strings.Cut(format, "_")
+	sampleRate, err := strconv.Atoi(rate)
+	if !ok || err != nil || sampleRate <= 0 {
+		return 0, fmt.Errorf("invalid output format %q", format)
+	}
+
+	var bytesPerSample int
+	switch codec {
+	case "pcm":
+		bytesPerSample = 2
+	case "ulaw", "alaw":
+		bytesPerSample = 1
+	default:
+		return 0, fmt.Errorf("duration of %q audio cannot be derived from its size", format)
+	}
+
+	samples := int64(len(data) / bytesPerSample)
+	return time.Duration(samples * int64(time.Second) / int64(sampleRate)), nil
+}
+
+// Duration returns the duration of the audio the alignment segment belongs to, which ends with the
+// last character's start time plus its duration.
+func (s StreamingAlignmentSegment) Duration() time.Duration {
+	n := len(s.CharStartTimesMs)
+	if n == 0 || len(s.CharDurationsMs) < n {
+		return 0
+	}
+	return time.Duration(s.CharStartTimesMs[n-1]+s.CharDurationsMs[n-1]) * time.Millisecond
+}
+
+// PCMToWAV wraps audio data in the pcm_* output formats, i.e. 16-bit little-endian mono samples at the given
+// sample rate, in a WAV container.
+//
+// It returns the WAV file, which is the PCM data preceded by a 44-byte header.
+func PCMToWAV(pcm []byte, sampleRate int) []byte {
+	const channels, bitsPerSample = 1, 16
+	blockAlign := channels * bitsPerSample / 8
+
+	wav := make([]byte, 44, 44+len(pcm))
+	copy(wav[0:], "RIFF")
+	binary.LittleEndian.PutUint32(wav[4:], uint32(36+len(pcm)))
+	copy(wav[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(wav[16:], 16) // fmt chunk size
+	binary.LittleEndian.PutUint16(wav[20:], 1)  // PCM
+	binary.LittleEndian.PutUint16(wav[22:], channels)
+	binary.LittleEndian.PutUint32(wav[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(wav[28:], uint32(sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(wav[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(wav[34:], bitsPerSample)
+	copy(wav[36:], "data")
+	binary.LittleEndian.PutUint32(wav[40:], uint32(len(pcm)))
+	return append(wav, pcm...)
+}
+
+// FrameWriter is an io.Writer that passes streamed audio on to an underlying writer in whole frames, so that each
+// write can be decoded on its own, e.g. when forwarding the audio of TextToSpeechStream over a channel to an
+// incremental decoder:
+//
+//	fw := elevenlabs.NewFrameWriter(w, elevenlabs.FormatMP3_44100_128)
+//	err := client.TextToSpeechStream(fw, voiceID, ttsReq, elevenlabs.OutputFormat(elevenlabs.FormatMP3_44100_128))
+//	if err == nil {
+//		err = fw.Flush()
+//	}
+//
+// The data is aligned according to its output format:
+//   - mp3_*: whole MPEG audio layer III frames, found by parsing their headers. An ID3v2 tag at the start of the
+//     stream is passed on as a whole, and bytes that aren't part of a frame are passed on with the next frame.
+//   - pcm_* and wav_*: whole 16-bit samples.
+//   - Other formats, e.g. ulaw_8000 or opus_*: as received, without buffering.
+//
+// Incomplete frames are buffered until the rest of the frame is written, or Flush is called.
+type FrameWriter struct {
+	w     io.Writer
+	align func(b []byte) int
+	buf   []byte
+}
+
+// NewFrameWriter returns a FrameWriter that writes audio in the given output format to w.
+func NewFrameWriter(w io.Writer, format string) *FrameWriter {
+	fw := &FrameWriter{w: w, align: func(b []byte) int { return len(b) }}
+	switch codec, _, _ := strings.Cut(format, "_"); codec {
+	case "mp3":
+		fw.align = alignMP3
+	case "pcm", "wav":
+		fw.align = func(b []byte) int { return len(b) &^ 1 }
+	}
+	return fw
+}
+
+// Write buffers p and writes the whole frames buffered so far to the underlying writer, if any.
+//
+// It returns len(p) and a nil error, unless writing to the underlying writer fails.
+func (f *FrameWriter) Write(p []byte) (int, error) {
+	f.buf = append(f.buf, p...)
+	n := f.align(f.buf)
+	if n == 0 {
+		return len(p), nil
+	}
+	_, err := f.w.Write(f.buf[:n])
+	f.buf = append(f.buf[:0], f.buf[n:]...)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the remaining buffered data, an incomplete frame at the end of the stream, to the underlying writer.
+func (f *FrameWriter) Flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	_, err := f.w.Write(f.buf)
+	f.buf = f.buf[:0]
+	return err
+}
+
+// mp3Bitrates are the bitrates of MPEG audio layer III in kbit/s, by MPEG version (MPEG-1, then MPEG-2 and 2.5)
+// and bitrate index. Index 0 is the free format, which is not supported, and index 15 is invalid.
+var mp3Bitrates = [2][15]int{
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+// mp3SampleRates are the sample rates of MPEG audio by MPEG version (MPEG-1, MPEG-2 and MPEG-2.5) and sample
+// rate index.
+var mp3SampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// alignMP3 returns the length of the longest prefix of b made of whole MP3 frames and ID3v2 tags, including the
+// bytes in between that aren't part of either.
+func alignMP3(b []byte) int {
+	end := 0
+	for pos := 0; pos+4 <= len(b); {
+		var n int
+		if string(b[pos:pos+3]) == "ID3" {
+			if pos+10 > len(b) {
+				break
+			}
+			// The tag size is a 28-bit "syncsafe" integer, which excludes the header and footer.
+			n = 10 + (int(b[pos+6]&0x7f)<<21 | int(b[pos+7]&0x7f)<<14 | int(b[pos+8]&0x7f)<<7 | int(b[pos+9]&0x7f))
+			if b[pos+5]&0x10 != 0 {
+				n += 10
+			}
+		} else {
+			n = mp3FrameLen(b[pos : pos+4])
+		}
+		if n == 0 {
+			// Not the start of a frame, the byte is passed on with the next frame.
+			pos++
+			continue
+		}
+		if pos+n > len(b) {
+			break
+		}
+		pos += n
+		end = pos
+	}
+	return end
+}
+
+// mp3FrameLen returns the length of the MPEG audio layer III frame starting with the 4-byte header h, or 0 if h
+// isn't a valid frame header.
+func mp3FrameLen(h []byte) int {
+	if h[0] != 0xff || h[1]&0xe0 != 0xe0 || (h[1]>>1)&3 != 1 {
+		return 0
+	}
+	var version int
+	switch (h[1] >> 3) & 3 {
+	case 3:
+		version = 0 // MPEG-1
+	case 2:
+		version = 1 // MPEG-2
+	case 0:
+		version = 2 // MPEG-2.5
+	default:
+		return 0
+	}
+	bitrateIdx, sampleRateIdx, padding := int(h[2]>>4), int(h[2]>>2)&3, int(h[2]>>1)&1
+	if bitrateIdx == 0 || bitrateIdx == 15 || sampleRateIdx == 3 {
+		return 0
+	}
+	// Frames hold 1152 samples with MPEG-1 and 576 otherwise, so they are 1152/8 or 576/8 times the bitrate
+	// divided by the sample rate long, in bytes.
+	bitrates, factor := mp3Bitrates[1], 72
+	if version == 0 {
+		bitrates, factor = mp3Bitrates[0], 144
+	}
+	return factor*bitrates[bitrateIdx]*1000/mp3SampleRates[version][sampleRateIdx] + padding
+}
+
+// wavOutput reports whether queries select one of the wav_* pseudo-formats, which the API doesn't support.
+// If so, it returns the sample rate and queries extended to request the pcm_* format with the same sample
+// rate instead, so that the result can be wrapped with PCMToWAV.
+func wavOutput(queries []QueryFunc) ([]QueryFunc, int, bool) {
+	q := url.Values{}
+	for _, qf := range queries {
+		qf(&q)
+	}
+	format := q.Get("output_format")
+	rate := strings.TrimPrefix(format, "wav_")
+	sampleRate, err := strconv.Atoi(rate)
+	if rate == format || err != nil || sampleRate <= 0 {
+		return queries, 0, false
+	}
+
+	pcmQueries := make([]QueryFunc, len(queries), len(queries)+1)
+	copy(pcmQueries, queries)
+	pcmQueries = append(pcmQueries, func(q *url.Values) {
+		q.Set("output_format", "pcm_"+rate)
+	})
+	return pcmQueries, sampleRate, true
+}
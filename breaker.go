@@ -0,0 +1,63 @@
+package elevenlabs
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreaker short-circuits requests for a cooldown period once threshold consecutive requests failed,
+// so that an API in trouble isn't sent requests that are bound to fail. It is safe for concurrent use and is
+// shared by all copies of a Client made with With. A nil *circuitBreaker lets all requests through.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	cooldown    time.Duration
+	failures    int
+	lastFailure time.Time
+	openUntil   time.Time
+}
+
+// allow returns ErrCircuitOpen if the circuit is open, or nil if a request can be sent.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record records the outcome of a request. The circuit opens once threshold failures were recorded in a row,
+// each within cooldown of the previous one. Once the cooldown is over, a single failure opens it again, until a
+// request succeeds.
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		return
+	}
+	now := time.Now()
+	if b.failures > 0 && b.failures < b.threshold && now.Sub(b.lastFailure) > b.cooldown {
+		// The previous failures are too old to be part of the same outage.
+		b.failures = 0
+	}
+	b.failures++
+	b.lastFailure = now
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// isOutageStatus reports whether a response with the given status counts as a failure for the circuit
+// breaker. Errors caused by the request itself, such as validation errors, don't.
+func isOutageStatus(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
@@ -0,0 +1,68 @@
+package elevenlabs
+
+import "sync"
+
+// cache holds a value retrieved from the API so that it can be reused by subsequent calls. It is safe for
+// concurrent use and is shared by all copies of a Client made with With.
+type cache[T any] struct {
+	mu    sync.Mutex
+	value T
+	valid bool
+}
+
+// get returns the cached value, calling fetch to retrieve it first if the cache is empty. Errors returned
+// by fetch are not cached.
+func (c *cache[T]) get(fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid {
+		return c.value, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	c.value, c.valid = v, true
+	return v, nil
+}
+
+// reset empties the cache.
+func (c *cache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero T
+	c.value, c.valid = zero, false
+}
+
+// conditionalCache holds the responses to GET requests along with their validators, so that they can be
+// requested again conditionally and reused when the API responds with 304 Not Modified. It is safe for
+// concurrent use and is shared by all copies of a Client made with With.
+type conditionalCache struct {
+	mu      sync.Mutex
+	entries map[string]conditionalEntry
+}
+
+// conditionalEntry is a response cached by conditionalCache.
+type conditionalEntry struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// get returns the cached response to a request to url, if any.
+func (c *conditionalCache) get(url string) (conditionalEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+// set caches the response to a request to url.
+func (c *conditionalCache) set(url string, e conditionalEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]conditionalEntry{}
+	}
+	c.entries[url] = e
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type textChunk struct {
@@ -12,18 +14,30 @@ type textChunk struct {
 	TryTriggerGeneration bool   `json:"try_trigger_generation"`
 }
 
-type streamingInputResponse struct {
-	Audio string `json:"audio"`
+// ChunkerConfig controls how text is split before it is sent to the stream-input API, see
+// NewTextChunkerWithConfig and WithStreamCoalesce.
+type ChunkerConfig struct {
+	// CoalesceChars is the number of characters (runes) words are coalesced up to before being sent, which
+	// reduces the number of frames sent for long texts. The default of 0 sends every word on its own.
+	CoalesceChars int
 }
 
-// readText reads from an io.Reader and sends the text over a channel.
-func readText(r io.Reader, text chan<- string) {
+// readText reads from an io.Reader and sends the text over a channel, one word at a time or, if cfg sets
+// CoalesceChars, as groups of words of at least that many characters.
+func readText(r io.Reader, text chan<- string, cfg ChunkerConfig) {
 	scanner := bufio.NewScanner(r)
 	scanner.Split(bufio.ScanWords)
 
+	buffer := ""
 	for scanner.Scan() {
-		word := scanner.Text()
-		text <- fmt.Sprintf("%s ", word)
+		buffer += fmt.Sprintf("%s ", scanner.Text())
+		if utf8.RuneCountInString(buffer) >= cfg.CoalesceChars {
+			text <- buffer
+			buffer = ""
+		}
+	}
+	if buffer != "" {
+		text <- buffer
 	}
 
 	// close(text)
@@ -43,13 +57,14 @@ func textChunker(chunks chan<- string, text <-chan string) {
 			}
 			buffer = text
 		} else if startsWithAny(text, splitters) {
-			output := buffer + string(text[0])
+			runes := []rune(text)
+			output := buffer + string(runes[0])
 			if endsWith(output, " ") {
 				chunks <- output
 			} else {
 				chunks <- output + " "
 			}
-			buffer = text[1:]
+			buffer = string(runes[1:])
 		} else {
 			buffer += text
 		}
@@ -61,6 +76,148 @@ func textChunker(chunks chan<- string, text <-chan string) {
 	close(chunks)
 }
 
+// NewTextChunker splits the text read from r into chunks at natural boundaries such as punctuation and
+// spaces, the same way text is batched for the stream-input API.
+//
+// It returns a channel over which the chunks are sent. The channel is closed once r has been read to the
+// end or returns an error, and must be drained for the goroutines reading r to exit.
+func NewTextChunker(r io.Reader) <-chan string {
+	return newTextChunker(r, ChunkerConfig{})
+}
+
+// NewTextChunkerWithConfig works like NewTextChunker, but splits the text as configured by cfg, e.g. to
+// coalesce words into longer chunks.
+func NewTextChunkerWithConfig(r io.Reader, cfg ChunkerConfig) <-chan string {
+	return newTextChunker(r, cfg)
+}
+
+// newTextChunker works like NewTextChunkerWithConfig, but returns a bidirectional channel, as
+// TextToSpeechInputStream expects.
+func newTextChunker(r io.Reader, cfg ChunkerConfig) chan string {
+	text := make(chan string)
+	chunks := make(chan string)
+	go func() {
+		readText(r, text, cfg)
+		close(text)
+	}()
+	go textChunker(chunks, text)
+	return chunks
+}
+
+// ChunkText splits text into chunks the same way as NewTextChunker.
+//
+// It returns a slice with the chunks in order.
+func ChunkText(text string) []string {
+	var chunks []string
+	for chunk := range NewTextChunker(strings.NewReader(text)) {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// splitText splits text into chunks of at most maxChars characters (runes). Chunks are made of whole
+// sentences where possible, sentences longer than maxChars are split between words and words longer than
+// maxChars are split between characters. Tags such as <break time="1s" /> are never split.
+func splitText(text string, maxChars int) []string {
+	var chunks []string
+	for _, sentence := range splitSentences(text) {
+		if utf8.RuneCountInString(sentence) <= maxChars {
+			chunks = appendPacked(chunks, sentence, maxChars)
+			continue
+		}
+		for _, word := range markupFields(sentence) {
+			if tagPattern.MatchString(word) {
+				// Tags are kept whole, even if they are longer than maxChars.
+				chunks = appendPacked(chunks, word, maxChars)
+				continue
+			}
+			for _, part := range splitRunes(word, maxChars) {
+				chunks = appendPacked(chunks, part, maxChars)
+			}
+		}
+	}
+	return chunks
+}
+
+// splitSentences splits text into sentences, normalizing the whitespace between words to a single space.
+func splitSentences(text string) []string {
+	terminators := []string{".", "?", "!", ";", "…"}
+	var sentences []string
+	sentence := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		if sentence != "" {
+			sentence += " "
+		}
+		sentence += scanner.Text()
+		if endsWithAny(sentence, terminators) {
+			sentences = append(sentences, sentence)
+			sentence = ""
+		}
+	}
+	if sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// markupFields splits s into words like strings.Fields, except that tags such as <break time="1s" /> are kept
+// whole so that they aren't split across chunks.
+func markupFields(s string) []string {
+	var words []string
+	inTag := false
+	for _, field := range strings.Fields(s) {
+		if inTag {
+			words[len(words)-1] += " " + field
+		} else {
+			words = append(words, field)
+		}
+		if opensTag(field) {
+			inTag = true
+		} else if strings.Contains(field, ">") {
+			inTag = false
+		}
+	}
+	return words
+}
+
+// opensTag reports whether field starts a tag that isn't closed within it, such as `<break` in
+// `<break time="1s" />`. As with tagPattern, a tag starts with a '<' followed by a letter or a '/', so that a
+// bare '<', e.g. in "a < b", is treated as text.
+func opensTag(field string) bool {
+	i := strings.LastIndex(field, "<")
+	for i >= 0 {
+		rest := field[i+1:]
+		if r, _ := utf8.DecodeRuneInString(rest); r == '/' || unicode.IsLetter(r) {
+			return !strings.Contains(rest, ">")
+		}
+		i = strings.LastIndex(field[:i], "<")
+	}
+	return false
+}
+
+// appendPacked appends s to the last chunk if the result fits within maxChars, or as a new chunk otherwise.
+func appendPacked(chunks []string, s string, maxChars int) []string {
+	if n := len(chunks); n > 0 && utf8.RuneCountInString(chunks[n-1])+1+utf8.RuneCountInString(s) <= maxChars {
+		chunks[n-1] += " " + s
+		return chunks
+	}
+	return append(chunks, s)
+}
+
+// splitRunes splits s into parts of at most n runes.
+func splitRunes(s string, n int) []string {
+	runes := []rune(s)
+	var parts []string
+	for len(runes) > n {
+		parts = append(parts, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return append(parts, string(runes))
+}
+
 // endsWithAny checks if the given string ends with any of the specified substrings.
 func endsWithAny(s string, subs []string) bool {
 	for _, sub := range subs {
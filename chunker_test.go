@@ -0,0 +1,150 @@
+package elevenlabs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitText(t *testing.T) {
+	testCases := []struct {
+		name      string
+		text      string
+		maxChars  int
+		expChunks []string
+	}{
+		{
+			name:      "sentences packed up to the limit",
+			text:      "One two. Three four!  Five six?\nSeven.",
+			maxChars:  20,
+			expChunks: []string{"One two. Three four!", "Five six? Seven."},
+		},
+		{
+			name:      "sentence longer than the limit is split between words",
+			text:      "Short. This sentence is much too long.",
+			maxChars:  12,
+			expChunks: []string{"Short. This", "sentence is", "much too", "long."},
+		},
+		{
+			name:      "word longer than the limit is split between characters",
+			text:      "日本語のテキスト",
+			maxChars:  3,
+			expChunks: []string{"日本語", "のテキ", "スト"},
+		},
+		{
+			name:      "tags are not split",
+			text:      `Wait for it <break time="1.5s" /> and go`,
+			maxChars:  12,
+			expChunks: []string{"Wait for it", `<break time="1.5s" />`, "and go"},
+		},
+		{
+			name:      "bare less-than sign is text",
+			text:      "if a < b then swap them",
+			maxChars:  10,
+			expChunks: []string{"if a < b", "then swap", "them"},
+		},
+		{
+			name:     "empty text",
+			text:     "  ",
+			maxChars: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks := splitText(tc.text, tc.maxChars)
+			if !reflect.DeepEqual(tc.expChunks, chunks) {
+				t.Errorf("Expected chunks %q, got %q", tc.expChunks, chunks)
+			}
+		})
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	exp := []string{"Hello, ", "world! ", "How ", "are ", "you? "}
+	if got := ChunkText("Hello, world!\nHow are   you?"); !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected chunks %q, got %q", exp, got)
+	}
+	if got := ChunkText(""); got != nil {
+		t.Errorf("Expected no chunks for empty text, got %q", got)
+	}
+}
+
+func TestTextChunkerMultibyteSplitter(t *testing.T) {
+	text := make(chan string)
+	chunks := make(chan string)
+	go textChunker(chunks, text)
+	go func() {
+		for _, s := range []string{"Wait", "—and ", "then"} {
+			text <- s
+		}
+		close(text)
+	}()
+
+	var got []string
+	for chunk := range chunks {
+		if !utf8.ValidString(chunk) {
+			t.Errorf("Expected valid UTF-8, got chunk %q", chunk)
+		}
+		got = append(got, chunk)
+	}
+	exp := []string{"Wait— ", "and ", "then"}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected chunks %q, got %q", exp, got)
+	}
+}
+
+func TestReadText(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     ChunkerConfig
+		expText []string
+	}{
+		{
+			name:    "one word at a time by default",
+			expText: []string{"The ", "quick ", "brown ", "fox ", "jumps. "},
+		},
+		{
+			name:    "words coalesced up to the target length",
+			cfg:     ChunkerConfig{CoalesceChars: 10},
+			expText: []string{"The quick ", "brown fox ", "jumps. "},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text := make(chan string)
+			go func() {
+				readText(strings.NewReader("The  quick\nbrown fox jumps."), text, tc.cfg)
+				close(text)
+			}()
+			var got []string
+			for s := range text {
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(tc.expText, got) {
+				t.Errorf("Expected text %q, got %q", tc.expText, got)
+			}
+		})
+	}
+}
+
+func BenchmarkReadText(b *testing.B) {
+	input := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 200)
+	for _, cfg := range []ChunkerConfig{{}, {CoalesceChars: 50}, {CoalesceChars: 200}} {
+		b.Run(fmt.Sprintf("CoalesceChars=%d", cfg.CoalesceChars), func(b *testing.B) {
+			var frames int
+			for i := 0; i < b.N; i++ {
+				text := make(chan string, 16)
+				go func() {
+					readText(strings.NewReader(input), text, cfg)
+					close(text)
+				}()
+				for range text {
+					frames++
+				}
+			}
+			b.ReportMetric(float64(frames)/float64(b.N), "frames/op")
+		})
+	}
+}
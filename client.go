@@ -4,20 +4,28 @@
 package elevenlabs
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	neturl "net/url"
+	"os"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 )
@@ -27,6 +35,10 @@ const (
 	elevenlabsBaseWSURL = "wss://api.elevenlabs.io/v1"
 	defaultTimeout      = 30 * time.Second
 	contentTypeJSON     = "application/json"
+
+	// envAPIKey and envBaseURL are the environment variables read by NewClientFromEnv.
+	envAPIKey  = "ELEVENLABS_API_KEY"
+	envBaseURL = "ELEVENLABS_BASE_URL"
 )
 
 var (
@@ -45,36 +57,121 @@ type QueryFunc func(*url.Values)
 // only a single instance of Client will ever be used by the program. The default client's API key and timeout
 // (which defaults to 30 seconds) can be modified with SetAPIKey and SetTimeout respectively, but the parent
 // context is fixed and is set to context.Background().
+//
+// The timeout applies to the whole duration of requests, except for streams (TextToSpeechStream,
+// StreamHistoryItemAudio, StreamDownloadHistoryAudio, DownloadProjectSnapshot and the WebSocket connection
+// of TextToSpeechInputStream), for which it only covers connecting and receiving the first bytes, so that
+// streams that legitimately take longer than the timeout aren't cut short.
 type Client struct {
+	// mu guards apiKey and timeout, which can be changed on the default client with SetAPIKey
+	// and SetTimeout while requests are in flight.
+	mu        *sync.RWMutex
 	baseURL   string
 	baseWSUrl string
 	apiKey    string
+	noAPIKey  bool
 	timeout   time.Duration
 	ctx       context.Context
+	cancel    context.CancelFunc
+	// ownsContext is set on clients whose context was created for them rather than shared with the client they
+	// were copied from with With, which are the ones Close cancels.
+	ownsContext bool
+
+	httpClient *http.Client
+	// transport is the transport of httpClient if the client created it, which Close closes the idle
+	// connections of. It is nil for an *http.Client set with WithHTTPClient and for copies made with With.
+	transport *http.Transport
+	headers   http.Header
+	wsDialer  *websocket.Dialer
+	logger    *log.Logger
+
+	streamReconnects  int
+	streamKeepAlive   time.Duration
+	streamIdleTimeout time.Duration
+	streamCoalesce    int
+	streamRawAudio    bool
+	streamProgress    func(StreamProgress)
+	progressInterval  time.Duration
+
+	validateLanguage bool
+	validateModel    bool
+	sanitizeMode     SanitizeMode
+	batchFailFast    bool
+	models           *cache[[]Model]
+
+	cacheDefaultSettings bool
+	defaultSettings      *cache[VoiceSettings]
+
+	conditionalRequests bool
+	conditional         *conditionalCache
+
+	breaker *circuitBreaker
+
+	maxResponseBytes int64
+	dumps            *dumpRecorder
+
+	// OnRequest, if set, is called right before a request is sent to the API.
+	//
+	// Hooks run synchronously in the request path, so they should return quickly. They are
+	// called regardless of logging and are meant for collecting metrics or creating tracing spans.
+	OnRequest func(RequestEvent)
+
+	// OnResponse, if set, is called once a response is received from the API or the request fails.
+	//
+	// Like OnRequest, it runs synchronously in the request path.
+	OnResponse func(ResponseEvent)
+}
+
+// RequestEvent describes a request that is about to be sent to the API. It is passed to the
+// Client's OnRequest hook.
+type RequestEvent struct {
+	Method string
+	URL    string
+}
+
+// ResponseEvent describes the outcome of a request made to the API. It is passed to the
+// Client's OnResponse hook.
+//
+// StatusCode is 0 if no response was received, in which case Err holds the reason.
+type ResponseEvent struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
 }
 
 func getDefaultClient() *Client {
 	once.Do(func() {
-		defaultClient = NewClient(context.Background(), "", defaultTimeout)
+		defaultClient = NewClientFromEnv()
 	})
 	return defaultClient
 }
 
 // SetAPIKey sets the API key for the default client.
 //
-// It should be called before making any API calls with the default client if
-// authentication is needed.
+// It should be called before making any API calls with the default client, which
+// fail with ErrMissingAPIKey until an API key is set, unless the ELEVENLABS_API_KEY
+// environment variable was set when the default client was first used (see NewClientFromEnv).
 // The function takes a string argument which is the API key to be set.
+// It is safe to call concurrently with requests made using the default client.
 func SetAPIKey(apiKey string) {
-	getDefaultClient().apiKey = apiKey
+	c := getDefaultClient()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiKey = apiKey
 }
 
 // SetTimeout sets the timeout duration for the default client.
 //
 // It can be called if a custom timeout settings are required for API calls.
 // The function takes a time.Duration argument which is the timeout to be set.
+// It is safe to call concurrently with requests made using the default client.
 func SetTimeout(timeout time.Duration) {
-	getDefaultClient().timeout = timeout
+	c := getDefaultClient()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = timeout
 }
 
 // NewClient creates and returns a new Client object with provided settings.
@@ -85,103 +182,866 @@ func SetTimeout(timeout time.Duration) {
 // client, a string argument that represents the API key to be used for authenticated requests and
 // a time.Duration argument that represents the timeout duration for the client's requests.
 //
-// It returns a pointer to a newly created Client.
+// Once the context is done, requests in flight are aborted and return its error. Streams stop cleanly: the audio
+// received so far has been written to their io.Writer and WebSocket connections are closed with a close message.
+// A context from signal.NotifyContext thus lets command-line tools stop streaming on Ctrl-C.
+//
+// It returns a pointer to a newly created Client. It is equivalent to:
+//
+//	elevenlabs.NewClientWithOptions(apiKey, elevenlabs.WithContext(ctx), elevenlabs.WithTimeout(reqTimeout))
 func NewClient(ctx context.Context, apiKey string, reqTimeout time.Duration) *Client {
-	return &Client{baseURL: elevenlabsBaseURL, baseWSUrl: elevenlabsBaseWSURL, apiKey: apiKey, timeout: reqTimeout, ctx: ctx}
+	return NewClientWithOptions(apiKey, WithContext(ctx), WithTimeout(reqTimeout))
+}
+
+// NewClientWithOptions creates and returns a new Client object authenticated with the given API key and
+// configured with the given options, e.g.:
+//
+//	client := elevenlabs.NewClientWithOptions(apiKey,
+//		elevenlabs.WithContext(ctx),
+//		elevenlabs.WithTimeout(time.Minute),
+//		elevenlabs.WithLogger(logger),
+//	)
+//
+// Without options, the client uses context.Background() as its parent context and a timeout of 30 seconds.
+//
+// It returns a pointer to a newly created Client.
+func NewClientWithOptions(apiKey string, opts ...Option) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{mu: &sync.RWMutex{}, baseURL: elevenlabsBaseURL, baseWSUrl: elevenlabsBaseWSURL, apiKey: apiKey, timeout: defaultTimeout, ctx: ctx, cancel: cancel, ownsContext: true, httpClient: &http.Client{}, models: &cache[[]Model]{}, defaultSettings: &cache[VoiceSettings]{}, conditional: &conditionalCache{}}
+	// The client gets its own transport, so that Close doesn't close the connections of http.DefaultTransport,
+	// which other packages use.
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		c.transport = t.Clone()
+		c.httpClient.Transport = c.transport
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewClientFromEnv creates and returns a new Client object configured from the environment and with the given
+// options, like NewClientWithOptions.
+//
+// The API key is read from the ELEVENLABS_API_KEY environment variable and, if set, the base URL of the API from
+// ELEVENLABS_BASE_URL, from which the base URL of the WebSocket API is derived by changing its scheme. The given
+// options take precedence over the environment, e.g. WithAPIKey overrides ELEVENLABS_API_KEY. The default client
+// used by the package-level functions is created this way.
+//
+// It returns a pointer to a newly created Client.
+func NewClientFromEnv(opts ...Option) *Client {
+	var envOpts []Option
+	if baseURL := os.Getenv(envBaseURL); baseURL != "" {
+		envOpts = append(envOpts, WithBaseURL(baseURL))
+		if strings.HasPrefix(baseURL, "http") {
+			envOpts = append(envOpts, WithBaseWSURL("ws"+strings.TrimPrefix(baseURL, "http")))
+		}
+	}
+	return NewClientWithOptions(os.Getenv(envAPIKey), append(envOpts, opts...)...)
+}
+
+// Option represents the type of functions that modify the settings of a Client.
+type Option func(*Client)
+
+// WithAPIKey returns an Option that sets the API key sent in the 'xi-api-key' header. It is meant
+// to be used with With to make calls on behalf of a different account than the one the client was
+// created with.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+		c.noAPIKey = false
+		// Cached responses may differ between accounts.
+		c.models = &cache[[]Model]{}
+		c.defaultSettings = &cache[VoiceSettings]{}
+		c.conditional = &conditionalCache{}
+	}
+}
+
+// WithContext returns an Option that sets the parent context of the client's requests and WebSocket streams.
+// Canceling ctx aborts them, as does calling Close on the client. When used with With, the returned client gets
+// its own context, derived from ctx, which Close cancels without affecting the original client.
+func WithContext(ctx context.Context) Option {
+	return func(c *Client) {
+		c.ctx, c.cancel = context.WithCancel(ctx)
+		c.ownsContext = true
+	}
+}
+
+// WithBaseURL returns an Option that sets the base URL of the API, e.g. to send requests through a gateway or
+// to a test server. It defaults to "https://api.elevenlabs.io/v1".
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithBaseWSURL returns an Option that sets the base URL of the WebSocket API used by TextToSpeechInputStream.
+// It defaults to "wss://api.elevenlabs.io/v1".
+func WithBaseWSURL(baseWSURL string) Option {
+	return func(c *Client) {
+		c.baseWSUrl = baseWSURL
+	}
+}
+
+// WithLogger returns an Option that sets the logger the client writes its debug and error messages to. By
+// default, they are written to the standard logger of the log package. They can be discarded with:
+//
+//	elevenlabs.WithLogger(log.New(io.Discard, "", 0))
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithoutAPIKey returns an Option that makes the client send its requests without an API key, for endpoints that
+// don't require one. Without it, requests made by a client that has no API key fail with ErrMissingAPIKey before
+// being sent.
+func WithoutAPIKey() Option {
+	return func(c *Client) {
+		WithAPIKey("")(c)
+		c.noAPIKey = true
+	}
+}
+
+// WithTimeout returns an Option that sets the timeout of the client's requests. It is meant to be used with
+// With to override the timeout of a single call, without changing the timeout of the shared client:
+//
+//	models, err := client.With(elevenlabs.WithTimeout(5 * time.Second)).GetModels()
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClient returns an Option that sets the *http.Client used to send requests to the API.
+//
+// Requests are always created with a context derived from the client's parent context, so a custom
+// transport can be used to propagate tracing information. For example, with OpenTelemetry:
+//
+//	client = client.With(elevenlabs.WithHTTPClient(&http.Client{
+//		Transport: otelhttp.NewTransport(http.DefaultTransport),
+//	}))
+//
+// The WebSocket connection used by TextToSpeechInputStream is dialed with the same parent context.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+		c.transport = nil
+	}
+}
+
+// WithDialer returns an Option that sets the dialer used to establish the WebSocket connection of
+// TextToSpeechInputStream, e.g. to connect through a proxy or with a custom TLS configuration:
+//
+//	client = client.With(elevenlabs.WithDialer(&websocket.Dialer{
+//		Proxy:           http.ProxyURL(proxyURL),
+//		TLSClientConfig: tlsConfig,
+//	}))
+//
+// The dialer is copied before use. If its HandshakeTimeout is zero, the timeout of the client is used. By default,
+// the proxy and TLS configuration of the client's *http.Client are used, if it has an *http.Transport.
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.wsDialer = dialer
+	}
+}
+
+// WithHeader returns an Option that adds a header sent with every request to the API, including the WebSocket
+// connection of TextToSpeechInputStream, e.g. a header required by a proxy. It can be used multiple times to
+// add several values for the same key. The 'xi-api-key' and 'Content-Type' headers are set by the client and
+// can't be overridden. The 'Accept' header, which defaults to '*/*', can be, e.g. to request a specific
+// content type:
+//
+//	err := client.With(elevenlabs.WithHeader("Accept", "audio/mpeg")).TextToSpeechStream(w, voiceID, ttsReq)
+func WithHeader(key, value string) Option {
+	return WithHeaders(http.Header{key: {value}})
+}
+
+// WithHeaders returns an Option that adds all the given headers to the ones sent with every request, like WithHeader.
+func WithHeaders(header http.Header) Option {
+	return func(c *Client) {
+		// The headers are copied so that the client With was called on is left unchanged.
+		c.headers = c.headers.Clone()
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		for key, values := range header {
+			for _, value := range values {
+				c.headers.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithStreamReconnect returns an Option that enables automatic reconnection of the WebSocket connection
+// used by TextToSpeechInputStream, up to maxRetries times per stream.
+//
+// When the connection drops unexpectedly, it is re-established, the initial TextToSpeechInputStreamingRequest
+// is sent again and the text channel is consumed from where it left off. Audio that was already received is
+// not sent again, but text that was sent and not yet converted when the connection dropped is lost.
+func WithStreamReconnect(maxRetries int) Option {
+	return func(c *Client) {
+		c.streamReconnects = maxRetries
+	}
+}
+
+// WithStreamKeepAlive returns an Option that makes TextToSpeechInputStream send a single space, which the API
+// treats as a no-op, whenever no text was received on the text channel for the given interval.
+//
+// The API closes stream-input connections that receive no text for 20 seconds, so an interval below that
+// keeps the connection open during pauses, e.g. while waiting for a conversation partner. Keep-alive is
+// disabled by default.
+func WithStreamKeepAlive(interval time.Duration) Option {
+	return func(c *Client) {
+		c.streamKeepAlive = interval
+	}
+}
+
+// WithStreamCoalesce returns an Option that makes TextToSpeechInputStreamReader coalesce the words it reads into
+// chunks of at least n characters (runes) before sending them, as with ChunkerConfig.CoalesceChars, which
+// reduces the number of frames sent for long texts. By default, every word is sent on its own.
+func WithStreamCoalesce(n int) Option {
+	return func(c *Client) {
+		c.streamCoalesce = n
+	}
+}
+
+// WithStreamIdleTimeout returns an Option that aborts streams once no data was received for the given duration.
+//
+// It applies to the streaming methods, for which the timeout of the client only covers receiving the first bytes,
+// and lets a stalled stream be detected without capping the duration of legitimate long streams. For the
+// WebSocket connection of TextToSpeechInputStream, sending text also counts as activity, since no audio is
+// expected while no text is sent. Streams aborted this way return an error wrapping context.DeadlineExceeded.
+// The idle timeout is disabled by default.
+func WithStreamIdleTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.streamIdleTimeout = timeout
+	}
+}
+
+// WithStreamProgress returns an Option that makes the streaming methods call fn with the number of bytes of
+// audio written so far and the time elapsed since the stream started, every interval, including while no audio
+// is received. A last call, with Done set, is made once the stream ended. It lets UIs show the throughput of a
+// stream and detect streams that stall.
+//
+// It applies to the methods that stream audio, such as TextToSpeechStream and TextToSpeechInputStream. fn is
+// called from a separate goroutine, but never concurrently, and should return quickly. An interval of 0 or less
+// reports every write instead, from the loop that writes the audio.
+func WithStreamProgress(interval time.Duration, fn func(StreamProgress)) Option {
+	return func(c *Client) {
+		c.streamProgress = fn
+		c.progressInterval = interval
+	}
+}
+
+// WithRawBase64Audio returns an Option that makes TextToSpeechInputStream send the audio on the response channel
+// as received from the API, base64 encoded, in the AudioBase64 field of the StreamingOutputResponse values,
+// instead of decoding it and writing it to the audio io.Writer, which is then left unused and may be nil.
+//
+// This saves decoding and encoding the audio again when it is forwarded in a text format such as JSON, but the
+// audio is then only available through the response channel, which must not be nil. Decoding is the default.
+func WithRawBase64Audio() Option {
+	return func(c *Client) {
+		c.streamRawAudio = true
+	}
+}
+
+// WithLanguageValidation returns an Option that makes TextToSpeech, TextToSpeechLong and TextToSpeechStream
+// check, using ValidateLanguageForModel, that the request's LanguageCode is supported by its model before
+// sending the request, and BuildTextToSpeechRequest and BuildTextToSpeechStreamRequest before preparing it. Requests that
+// don't set both LanguageCode and ModelID are not checked.
+func WithLanguageValidation() Option {
+	return func(c *Client) {
+		c.validateLanguage = true
+	}
+}
+
+// WithModelValidation returns an Option that makes TextToSpeech, TextToSpeechLong and TextToSpeechStream check
+// the model of requests before sending them, and BuildTextToSpeechRequest and BuildTextToSpeechStreamRequest
+// before preparing them. Requests that don't set a model ID are sent with DefaultModel, the
+// model the API would use, and requests for a model that isn't a text-to-speech model known to this package
+// fail with the error of ValidateTextToSpeechModel.
+func WithModelValidation() Option {
+	return func(c *Client) {
+		c.validateModel = true
+	}
+}
+
+// WithTextSanitizing returns an Option that makes TextToSpeech, TextToSpeechLong and TextToSpeechStream check
+// the text of requests for tags the API doesn't interpret before sending them, and BuildTextToSpeechRequest
+// and BuildTextToSpeechStreamRequest before preparing them. With SanitizeStrip, the tags
+// are removed as with SanitizeText. With SanitizeError, an *UnsupportedTagError is returned instead, as with
+// ValidateText.
+func WithTextSanitizing(mode SanitizeMode) Option {
+	return func(c *Client) {
+		c.sanitizeMode = mode
+	}
+}
+
+// WithDefaultVoiceSettingsCache returns an Option that makes GetDefaultVoiceSettings cache the default voice
+// settings, which rarely change, so that only the first call sends a request. The cache is shared with the
+// clients created with With and can be refreshed with RefreshDefaultVoiceSettings. Caching is disabled by default.
+func WithDefaultVoiceSettingsCache() Option {
+	return func(c *Client) {
+		c.cacheDefaultSettings = true
+	}
+}
+
+// WithConditionalRequests returns an Option that makes GetModels, GetVoices and GetSharedVoices send conditional
+// requests, using the ETag and Last-Modified headers of their previous responses. When the API responds with 304
+// Not Modified, the previous response is reused, which saves bandwidth for programs that poll these methods. The
+// responses are cached for the lifetime of the client and shared with the clients created with With.
+func WithConditionalRequests() Option {
+	return func(c *Client) {
+		c.conditionalRequests = true
+	}
+}
+
+// WithCircuitBreaker returns an Option that stops sending requests for the cooldown period once threshold
+// consecutive requests failed, each within cooldown of the previous one. In the meantime, the methods of the
+// client return ErrCircuitOpen right away, which spares both the caller and the API during an outage.
+//
+// Requests that fail to be sent, responses with a 5xx or 429 status and stream-input connections that fail to
+// be established count as failures. Other error responses, e.g. for an invalid request, don't. Once the cooldown
+// is over, requests are sent again, and the circuit opens again at the first failure until a request succeeds.
+// The state of the circuit is shared with the clients created with With. A threshold of 0 or less disables it,
+// which is the default.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// WithMaxResponseBytes returns an Option that limits the size of the JSON and error response bodies the client
+// reads to n bytes, so that an unexpectedly large body can't exhaust memory, e.g. when proxying untrusted input.
+// Requests whose JSON response body is larger fail with an error matching ErrResponseTooLarge. Error responses
+// are truncated to n bytes instead, so that the error still carries their status, e.g. for ErrNotFound.
+//
+// Audio is not limited. The streaming methods, such as TextToSpeechStream, copy it to the caller's io.Writer as
+// it is received, but TextToSpeech and the other methods returning a []byte hold it in memory in full. A limit
+// of 0 or less disables it, which is the default.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithBatchFailFast returns an Option that makes TextToSpeechBatch stop starting new conversions once one of
+// them failed. By default, all texts are converted regardless of failures.
+func WithBatchFailFast() Option {
+	return func(c *Client) {
+		c.batchFailFast = true
+	}
+}
+
+// With returns a copy of the client with the given options applied.
+//
+// The original client is left unchanged, which makes With suitable for per-request overrides, for
+// example when a multi-tenant service needs to use a different API key for a single call:
+//
+//	audio, err := client.With(elevenlabs.WithAPIKey(tenantKey)).TextToSpeech(voiceID, ttsReq)
+//
+// It returns a pointer to the new Client.
+func (c *Client) With(opts ...Option) *Client {
+	c.mu.RLock()
+	cp := *c
+	c.mu.RUnlock()
+	cp.mu = &sync.RWMutex{}
+	cp.ownsContext = false
+	cp.transport = nil
+	for _, opt := range opts {
+		opt(&cp)
+	}
+	return &cp
+}
+
+// Close releases the resources held by the client. It cancels the client's context, which aborts requests and
+// WebSocket streams in flight, and closes the idle connections of the transport the client created. The
+// connections of an *http.Client set with WithHTTPClient are left to its owner.
+//
+// The client is unusable after Close: its methods return an error wrapping context.Canceled. Clients created
+// from it with With share its context and transport, so they are closed as well. Closing such a copy, on the
+// other hand, has no effect, so that it can't break the client it was copied from or the other copies, unless
+// it was given its own context with WithContext, which Close cancels. Close is meant for programs that create
+// and discard clients; there's no need to call it on a client that lives as long as the program.
+func (c *Client) Close() {
+	if c.ownsContext {
+		c.cancel()
+	}
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// checkAPIKey returns ErrMissingAPIKey if apiKey is empty, unless the client was configured with WithoutAPIKey.
+func (c *Client) checkAPIKey(apiKey string) error {
+	if apiKey == "" && !c.noAPIKey {
+		return ErrMissingAPIKey
+	}
+	return nil
+}
+
+// checkModel applies WithModelValidation to ttsReq, returning it with the default model set if it had none.
+func (c *Client) checkModel(ttsReq TextToSpeechRequest) (TextToSpeechRequest, error) {
+	if !c.validateModel {
+		return ttsReq, nil
+	}
+	if ttsReq.ModelID == "" {
+		return ttsReq.WithModel(DefaultModel), nil
+	}
+	return ttsReq, ValidateTextToSpeechModel(ttsReq.ModelID)
+}
+
+// prepareTextToSpeech applies the checks and transformations the client was configured with to ttsReq, i.e.
+// WithModelValidation, WithLanguageValidation and WithTextSanitizing, before it is sent or built by the
+// text-to-speech methods.
+func (c *Client) prepareTextToSpeech(ttsReq TextToSpeechRequest) (TextToSpeechRequest, error) {
+	ttsReq, err := c.checkModel(ttsReq)
+	if err != nil {
+		return ttsReq, err
+	}
+	if c.validateLanguage && ttsReq.LanguageCode != "" && ttsReq.ModelID != "" {
+		if err := c.ValidateLanguageForModel(ttsReq.ModelID, ttsReq.LanguageCode); err != nil {
+			return ttsReq, err
+		}
+	}
+	text, err := c.sanitize(ttsReq.Text)
+	if err != nil {
+		return ttsReq, err
+	}
+	ttsReq.Text = text
+	return ttsReq, nil
+}
+
+// logf writes a message to the client's logger, or the standard logger if none was set with WithLogger.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
+// settings returns the API key and timeout of the client.
+func (c *Client) settings() (string, time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.apiKey, c.timeout
+}
+
+// forEachConcurrently calls fn with every index from 0 to n-1, with up to workers calls in flight, and stops
+// calling it once the client's context is done or, if failFast is true, once a call returned an error. It
+// returns nil if all calls returned nil, or an error combining the errors returned by fn and the error of
+// the context.
+func (c *Client) forEachConcurrently(n, workers int, failFast bool, fn func(i int) error) error {
+	indexes := make(chan int)
+	errs := make(chan error, n)
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					errs <- err
+					if failFast {
+						failOnce.Do(func() { close(failed) })
+					}
+				}
+			}
+		}()
+	}
+
+	var ctxErr error
+dispatch:
+	for i := 0; i < n; i++ {
+		// Checked first, since select picks randomly among ready cases.
+		select {
+		case <-failed:
+			break dispatch
+		default:
+		}
+		select {
+		case indexes <- i:
+		case <-failed:
+			break dispatch
+		case <-c.ctx.Done():
+			ctxErr = c.ctx.Err()
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	close(errs)
+
+	var joined multiError
+	for err := range errs {
+		joined = append(joined, err)
+	}
+	if ctxErr != nil {
+		joined = append(joined, ctxErr)
+	}
+	if len(joined) == 0 {
+		return nil
+	}
+	return joined
 }
 
 func (c *Client) doRequest(ctx context.Context, RespBodyWriter io.Writer, method, urlStr string, bodyBuf io.Reader, contentType string, queries ...QueryFunc) error {
+	_, err := c.doRequestWithHeader(ctx, RespBodyWriter, method, urlStr, bodyBuf, contentType, false, nil, queries...)
+	return err
+}
+
+// doStreamRequest works like doRequest, except that the client's timeout only applies until the first bytes
+// of the response body are received, so that streams that take longer than the timeout aren't cut short.
+func (c *Client) doStreamRequest(ctx context.Context, RespBodyWriter io.Writer, method, urlStr string, bodyBuf io.Reader, contentType string, queries ...QueryFunc) error {
+	_, err := c.doRequestWithHeader(ctx, RespBodyWriter, method, urlStr, bodyBuf, contentType, true, nil, queries...)
+	return err
+}
+
+// doRequestWithHeader works like doRequest, or doStreamRequest if stream is true, but also sends extraHeader,
+// if not nil, and returns the header of a successful response. If the API responds with 304 Not Modified, the
+// header is returned along with errNotModified, provided extraHeader holds validators.
+func (c *Client) doRequestWithHeader(ctx context.Context, RespBodyWriter io.Writer, method, urlStr string, bodyBuf io.Reader, contentType string, stream bool, extraHeader http.Header, queries ...QueryFunc) (http.Header, error) {
+	// A context that is already done fails the request right away, rather than once the body was read and logged
+	// and the request prepared.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	dbgString := "✏️ ELEVENLABS [DEBUG] "
 	errorString := "✏️ \x1b[31mELEVENLABS [ERROR]\x1b[0m "
-	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	apiKey, timeout := c.settings()
+	if err := c.checkAPIKey(apiKey); err != nil {
+		return nil, err
+	}
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	var timeoutCtx context.Context
+	var cancel context.CancelFunc
+	var wd *watchdog
+	if stream {
+		timeoutCtx, cancel = context.WithCancel(ctx)
+		wd = newWatchdog(timeout, cancel)
+		defer wd.stop()
+		var done func()
+		RespBodyWriter, done = newProgressWriter(RespBodyWriter, c.streamProgress, c.progressInterval)
+		defer done()
+		RespBodyWriter = &watchedWriter{w: RespBodyWriter, wd: wd, idleTimeout: c.streamIdleTimeout}
+	} else {
+		timeoutCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
 	defer cancel()
+	// reqErr normalizes the errors caused by the request being canceled or timing out, so that canceling the
+	// parent context results in its error being returned as is, e.g. context.Canceled.
+	reqErr := func(err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return wd.err(err)
+	}
 
 	var bodyBytes []byte
 	if bodyBuf != nil {
 		buf, err := io.ReadAll(bodyBuf)
 		if err != nil {
-			log.Printf(dbgString+"failed to read body for logging: %v", err)
+			c.logf(dbgString+"failed to read body for logging: %v", err)
 		}
 		bodyBytes = buf
 		bodyBuf = bytes.NewReader(buf)
 	}
 
-	req, err := http.NewRequestWithContext(timeoutCtx, method, urlStr, bodyBuf)
-	if err != nil {
-		log.Printf(dbgString+"NewRequest error: %v", err)
-		return err
-	}
-
-	req.Header.Set("Accept", "*/*")
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+	header := c.requestHeader(apiKey, contentType)
+	for k, vals := range extraHeader {
+		header[k] = vals
 	}
-	if c.apiKey != "" {
-		req.Header.Set("xi-api-key", c.apiKey)
-	}
-
-	q := req.URL.Query()
-	for _, qf := range queries {
-		qf(&q)
+	req, err := newRequest(timeoutCtx, header, method, urlStr, bodyBuf, queries...)
+	if err != nil {
+		c.logf(dbgString+"NewRequest error: %v", err)
+		return nil, err
 	}
-	req.URL.RawQuery = q.Encode()
 
 	dumpReq, _ := httputil.DumpRequestOut(req, true)
-	log.Printf(dbgString+" >>> HTTP REQUEST >>>\n%s", string(dumpReq))
+	c.logf(dbgString+" >>> HTTP REQUEST >>>\n%s", string(dumpReq))
+	dump := c.dumps.start(dumpReq, apiKey)
+	defer dump.done()
 	if len(bodyBytes) > 0 {
-		log.Printf(dbgString+"Request Body:\n%s", string(bodyBytes))
+		c.logf(dbgString+"Request Body:\n%s", string(bodyBytes))
 	}
 
-	client := &http.Client{}
-	log.Printf(dbgString+"Sending request to %s …", req.URL.String())
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf(errorString+"client.Do error: %v", err)
-		return err
+	c.logf(dbgString+"Sending request to %s …", req.URL.String())
+	if c.OnRequest != nil {
+		c.OnRequest(RequestEvent{Method: req.Method, URL: req.URL.String()})
 	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		log.Printf(errorString+"reading resp.Body: %v", err)
-		return err
+		err = reqErr(err)
+		c.logf(errorString+"client.Do error: %v", err)
+		dump.notef("error: %v", err)
+		c.onResponse(req, 0, start, err)
+		// Requests canceled by the caller say nothing about the health of the API.
+		c.breaker.record(ctx.Err() == nil)
+		return nil, err
 	}
+	defer resp.Body.Close()
+	dump.response(resp)
+	c.onResponse(req, resp.StatusCode, start, nil)
+	c.breaker.record(isOutageStatus(resp.StatusCode))
 
-	log.Printf(dbgString+" <<< HTTP RESPONSE <<<\nStatus: %d %s\nHeaders:", resp.StatusCode, resp.Status)
+	c.logf(dbgString+" <<< HTTP RESPONSE <<<\nStatus: %d %s\nHeaders:", resp.StatusCode, resp.Status)
 	for k, vals := range resp.Header {
-		log.Printf("  %s: %s", k, strings.Join(vals, ", "))
+		c.logf("  %s: %s", k, strings.Join(vals, ", "))
 	}
-	log.Printf(dbgString+" Response body:\n%s", string(respBytes))
 
+	// A 304 is only expected in response to the validators of a conditional request, otherwise it's reported as
+	// an unexpected status rather than being mistaken for an empty success.
+	if resp.StatusCode == http.StatusNotModified && sentValidators(extraHeader) {
+		return resp.Header, errNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
+		// Error bodies are truncated rather than rejected, so that the error reflects the status.
+		body := io.Reader(resp.Body)
+		if c.maxResponseBytes > 0 {
+			body = io.LimitReader(resp.Body, c.maxResponseBytes)
+		}
+		respBytes, err := io.ReadAll(body)
+		if err != nil {
+			err = reqErr(err)
+			c.logf(errorString+"reading resp.Body: %v", err)
+			dump.notef("error: %v", err)
+			return nil, err
+		}
+		c.logf(dbgString+" Response body:\n%s", string(respBytes))
+		dump.body(respBytes)
+
 		switch resp.StatusCode {
 		case http.StatusBadRequest, http.StatusUnauthorized:
 			var apiErr APIError
 			if err := json.Unmarshal(respBytes, &apiErr); err != nil {
-				return fmt.Errorf("failed to unmarshal APIError: %w", err)
+				return nil, newUnexpectedStatusError(resp.StatusCode, respBytes)
 			}
-			return &apiErr
+			apiErr.HTTPStatus = resp.StatusCode
+			return nil, &apiErr
 
 		case http.StatusUnprocessableEntity:
 			var valErr ValidationError
 			if err := json.Unmarshal(respBytes, &valErr); err != nil {
-				return fmt.Errorf("failed to unmarshal ValidationError: %w", err)
+				return nil, newUnexpectedStatusError(resp.StatusCode, respBytes)
 			}
-			return &valErr
+			valErr.HTTPStatus = resp.StatusCode
+			return nil, &valErr
 
 		default:
-			return fmt.Errorf("unexpected HTTP status %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+			return nil, newUnexpectedStatusError(resp.StatusCode, respBytes)
+		}
+	}
+
+	// JSON bodies are small and worth logging, anything else (i.e. audio) is copied to
+	// RespBodyWriter as it arrives so that large responses are never held in memory.
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), contentTypeJSON) {
+		respBytes, err := readLimited(resp.Body, c.maxResponseBytes)
+		if err != nil {
+			if !errors.Is(err, ErrResponseTooLarge) {
+				err = reqErr(err)
+			}
+			c.logf(errorString+"reading resp.Body: %v", err)
+			dump.notef("error: %v", err)
+			return nil, err
 		}
+		c.logf(dbgString+" Response body:\n%s", string(respBytes))
+		dump.body(respBytes)
+		if _, err := RespBodyWriter.Write(respBytes); err != nil {
+			c.logf(errorString+" copying response to RespBodyWriter: %v", err)
+			return nil, err
+		}
+	} else {
+		n, err := io.Copy(RespBodyWriter, resp.Body)
+		if err != nil {
+			err = reqErr(err)
+			c.logf(errorString+" copying response to RespBodyWriter: %v", err)
+			dump.notef("%d bytes of %s, then error: %v", n, resp.Header.Get("Content-Type"), err)
+			return nil, err
+		}
+		c.logf(dbgString+" Response body: %d bytes copied", n)
+		dump.notef("%d bytes of %s", n, resp.Header.Get("Content-Type"))
+	}
+
+	c.logf(dbgString + " Request completed successfully")
+	return resp.Header, nil
+}
+
+// readLimited reads r to the end like io.ReadAll, but returns an error matching ErrResponseTooLarge once more
+// than limit bytes were read. A limit of 0 or less reads r without limit.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
 	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return b, nil
+}
 
-	reader := bytes.NewReader(respBytes)
-	if _, err := io.Copy(RespBodyWriter, reader); err != nil {
-		log.Printf(errorString+" copying response to RespBodyWriter: %v", err)
+// doConditionalRequest works like doRequest for GET requests without a body. If the client was configured with
+// WithConditionalRequests, the validators of the previous response to the same URL and queries are sent along,
+// and the body of that response is written to RespBodyWriter if the API responds with 304 Not Modified.
+func (c *Client) doConditionalRequest(ctx context.Context, RespBodyWriter io.Writer, urlStr string, queries ...QueryFunc) error {
+	if !c.conditionalRequests {
+		return c.doRequest(ctx, RespBodyWriter, http.MethodGet, urlStr, &bytes.Buffer{}, contentTypeJSON, queries...)
+	}
+
+	key := urlStr
+	q := url.Values{}
+	for _, qf := range queries {
+		qf(&q)
+	}
+	if len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	cached, ok := c.conditional.get(key)
+	var extraHeader http.Header
+	if ok {
+		extraHeader = http.Header{}
+		if cached.etag != "" {
+			extraHeader.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			extraHeader.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+	b := bytes.Buffer{}
+	header, err := c.doRequestWithHeader(ctx, &b, http.MethodGet, urlStr, &bytes.Buffer{}, contentTypeJSON, false, extraHeader, queries...)
+	if errors.Is(err, errNotModified) && ok {
+		_, err = RespBodyWriter.Write(cached.body)
 		return err
 	}
+	if err != nil {
+		return err
+	}
+	if etag, lastModified := header.Get("ETag"), header.Get("Last-Modified"); etag != "" || lastModified != "" {
+		c.conditional.set(key, conditionalEntry{etag: etag, lastModified: lastModified, body: b.Bytes()})
+	}
+	_, err = RespBodyWriter.Write(b.Bytes())
+	return err
+}
 
-	log.Printf(dbgString + " Request completed successfully")
-	return nil
+// sentValidators reports whether header holds the validators of a conditional request.
+func sentValidators(header http.Header) bool {
+	return header.Get("If-None-Match") != "" || header.Get("If-Modified-Since") != ""
+}
+
+// doReaderRequest works like doStreamRequest, except that the response body is returned as an io.ReadCloser
+// that is read as the body is received. It returns once the first bytes of the body are received or the request
+// fails, so that errors from the API are returned rather than surfacing when reading. Closing the reader aborts
+// the request and releases the connection.
+func (c *Client) doReaderRequest(ctx context.Context, method, urlStr string, bodyBuf io.Reader, contentType string, queries ...QueryFunc) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		err := c.doStreamRequest(ctx, &startWriter{w: pw, started: started}, method, urlStr, bodyBuf, contentType, queries...)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	select {
+	case <-started:
+		return pr, nil
+	case err := <-done:
+		if err != nil {
+			return nil, err
+		}
+		return pr, nil
+	}
+}
+
+// startWriter closes started on the first write, before passing it on to w.
+type startWriter struct {
+	w       io.Writer
+	started chan struct{}
+	once    sync.Once
+}
+
+func (s *startWriter) Write(p []byte) (int, error) {
+	s.once.Do(func() { close(s.started) })
+	return s.w.Write(p)
+}
+
+// requestHeader returns the header of an API request authenticated with apiKey. The extra headers set with
+// WithHeader and WithHeaders are included, but can't override the 'xi-api-key' and 'Content-Type' headers.
+// The 'Accept' header defaults to '*/*' unless it was set with them.
+func (c *Client) requestHeader(apiKey, contentType string) http.Header {
+	header := c.headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if header.Get("Accept") == "" {
+		header.Set("Accept", "*/*")
+	}
+	header.Del("Content-Type")
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Del("xi-api-key")
+	if apiKey != "" {
+		header.Set("xi-api-key", apiKey)
+	}
+	return header
+}
+
+// newRequest builds an API request with the given header, and the given queries applied to its URL.
+func newRequest(ctx context.Context, header http.Header, method, urlStr string, body io.Reader, queries ...QueryFunc) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+
+	q := req.URL.Query()
+	for _, qf := range queries {
+		qf(&q)
+	}
+	req.URL.RawQuery = q.Encode()
+	return req, nil
+}
+
+// buildRequest builds, without sending, the request that doRequest would send. The request is bound to
+// the client's context but not to its timeout, since the caller decides when and how it is sent.
+func (c *Client) buildRequest(method, urlStr string, body []byte, contentType string, queries ...QueryFunc) (*http.Request, error) {
+	apiKey, _ := c.settings()
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	return newRequest(c.ctx, c.requestHeader(apiKey, contentType), method, urlStr, bodyReader, queries...)
+}
+
+// onResponse calls the OnResponse hook, if set, with the outcome of req.
+func (c *Client) onResponse(req *http.Request, statusCode int, start time.Time, err error) {
+	if c.OnResponse == nil {
+		return
+	}
+	c.OnResponse(ResponseEvent{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: statusCode,
+		Duration:   time.Since(start),
+		Err:        err,
+	})
 }
 
 type StreamingInputResponse struct {
@@ -189,13 +1049,33 @@ type StreamingInputResponse struct {
 	IsFinal             bool                      `json:"isFinal"`
 	NormalizedAlignment StreamingAlignmentSegment `json:"normalizedAlignment"`
 	Alignment           StreamingAlignmentSegment `json:"alignment"`
+	Seed                *int                      `json:"seed,omitempty"`
+	RequestId           string                    `json:"request_id,omitempty"`
+	NextRequestIds      []string                  `json:"next_request_ids,omitempty"`
 }
 
+// StreamingOutputResponse is sent on the response channel of TextToSpeechInputStream for every message
+// received from the API.
+//
+// The API doesn't send the text a message's audio corresponds to, so Text is rebuilt from the characters
+// of Alignment, which makes it suitable for displaying captions in sync with the audio.
+//
+// Seed, RequestId and NextRequestIds are only set when the API includes them in a message. The request IDs
+// can be passed as PreviousRequestIds and NextRequestIds of later requests, to keep the prosody continuous
+// across segments generated separately.
+//
+// AudioBase64 is only set for clients configured with WithRawBase64Audio, and holds the audio of the message
+// as received from the API.
 type StreamingOutputResponse struct {
 	Audio               []byte                    `json:"audio"`
+	AudioBase64         string                    `json:"audioBase64,omitempty"`
 	IsFinal             bool                      `json:"isFinal"`
 	NormalizedAlignment StreamingAlignmentSegment `json:"normalizedAlignment"`
 	Alignment           StreamingAlignmentSegment `json:"alignment"`
+	Text                string                    `json:"text"`
+	Seed                *int                      `json:"seed,omitempty"`
+	RequestId           string                    `json:"request_id,omitempty"`
+	NextRequestIds      []string                  `json:"next_request_ids,omitempty"`
 }
 
 type StreamingAlignmentSegment struct {
@@ -204,21 +1084,30 @@ type StreamingAlignmentSegment struct {
 	Chars            []string `json:"chars"`
 }
 
+// Text returns the text the segment is aligned to, by joining its characters.
+func (s StreamingAlignmentSegment) Text() string {
+	return strings.Join(s.Chars, "")
+}
+
 type WsStreamingOutputChannel chan StreamingOutputResponse
 
-// AudioResponsePipe io.Writer,
+// doInputStreamingRequest dials the stream-input WebSocket endpoint, sends the initial request followed by
+// the text received on TextReader and forwards the audio and alignment information sent back by the API.
+//
+// If the client was configured with WithStreamReconnect, the connection is re-established after an
+// unexpected connection error and consumption of TextReader resumes where it left off.
 func (c *Client) doInputStreamingRequest(ctx context.Context, TextReader chan string, ResponseChannel chan StreamingOutputResponse, AudioResponsePipe io.Writer, url string, req TextToSpeechInputStreamingRequest, contentType string, queries ...QueryFunc) error {
-	driverActive := true // Driver shut down?
-	driverError := false // Unexpected errors
-
-	headers := http.Header{}
-	headers.Add("Accept", "*/*")
-	if contentType != "" {
-		headers.Add("Content-Type", contentType)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	apiKey, _ := c.settings()
+	if err := c.checkAPIKey(apiKey); err != nil {
+		return err
 	}
-	if c.apiKey != "" {
-		headers.Add("xi-api-key", c.apiKey)
+	if c.streamRawAudio && ResponseChannel == nil {
+		return errors.New("a response channel is required to receive raw base64 audio")
 	}
+	headers := c.requestHeader(apiKey, contentType)
 
 	u, err := neturl.Parse(url)
 	if err != nil {
@@ -229,142 +1118,416 @@ func (c *Client) doInputStreamingRequest(ctx context.Context, TextReader chan st
 	for _, qf := range queries {
 		qf(&q)
 	}
+	if err := validateInputStreamQuery(q); err != nil {
+		return err
+	}
 	u.RawQuery = q.Encode()
 
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), headers)
-	if err != nil {
-		return err
+	if AudioResponsePipe != nil {
+		var done func()
+		AudioResponsePipe, done = newProgressWriter(AudioResponsePipe, c.streamProgress, c.progressInterval)
+		defer done()
+	}
+
+	var pending *textChunk
+	for attempt := 0; ; attempt++ {
+		conn, err := c.dialInputStream(ctx, u.String(), headers)
+		if err != nil && attempt == 0 {
+			// Only failures to re-establish a dropped connection are retried.
+			return err
+		}
+		if err == nil {
+			pending, err = c.streamInput(ctx, conn, TextReader, ResponseChannel, AudioResponsePipe, req, pending)
+			conn.Close()
+		}
+		var sErr *streamError
+		if err == nil || errors.As(err, &sErr) || ctx.Err() != nil || attempt >= c.streamReconnects {
+			return err
+		}
+		c.logf("✏️ ELEVENLABS [DEBUG] stream-input connection lost (%v), reconnecting (%d/%d) …", err, attempt+1, c.streamReconnects)
+	}
+}
+
+// dialInputStream dials the stream-input WebSocket endpoint. The timeout of the client applies to establishing
+// the connection only, not to the session that follows.
+func (c *Client) dialInputStream(ctx context.Context, url string, headers http.Header) (*websocket.Conn, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	_, timeout := c.settings()
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, resp, err := c.dialer(timeout).DialContext(dialCtx, url, headers)
+	if err != nil && resp != nil {
+		c.breaker.record(isOutageStatus(resp.StatusCode))
+	} else {
+		c.breaker.record(err != nil && ctx.Err() == nil)
+	}
+	return conn, err
+}
+
+// dialer returns the dialer used for the WebSocket connection of TextToSpeechInputStream: a copy of the one set
+// with WithDialer or, by default, of websocket.DefaultDialer with the proxy and TLS configuration of the client's
+// *http.Client, if it uses an *http.Transport. The handshake timeout is set to the given timeout unless the dialer
+// set with WithDialer has one.
+func (c *Client) dialer(timeout time.Duration) *websocket.Dialer {
+	var d websocket.Dialer
+	if c.wsDialer != nil {
+		d = *c.wsDialer
+	} else {
+		d = *websocket.DefaultDialer
+		d.HandshakeTimeout = 0
+		if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+			d.Proxy = t.Proxy
+			d.TLSClientConfig = t.TLSClientConfig
+		}
+	}
+	if d.HandshakeTimeout == 0 {
+		d.HandshakeTimeout = timeout
 	}
-	defer conn.Close()
+	return &d
+}
 
-	// Send initial request
+// streamInput runs a stream-input session over an established connection. The session starts with the initial
+// request and, if not nil, the pending chunk that could not be sent over a previous connection.
+//
+// It returns the chunk that was being sent when the connection failed, if any, and an error. Errors caused by
+// the received data rather than by the connection are of type *streamError.
+func (c *Client) streamInput(ctx context.Context, conn *websocket.Conn, textReader <-chan string, responseChan chan<- StreamingOutputResponse, audioWriter io.Writer, req TextToSpeechInputStreamingRequest, pending *textChunk) (*textChunk, error) {
 	if err := conn.WriteJSON(req); err != nil {
-		return err
+		return pending, err
 	}
 
-	// Input watcher
-	inputCtx, inputCancel := context.WithCancel(context.Background())
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readInputStream(ctx, conn, responseChan, audioWriter, c.streamRawAudio, c.streamIdleTimeout)
+	}()
+	// abort closes the connection to stop the reader and waits for it to return. The API is told the session
+	// is over with a close message first, which fails harmlessly if the connection is already broken.
+	abort := func() {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		conn.Close()
+		<-readErr
+	}
 
-	errCh := make(chan error, 1)
-	var wg sync.WaitGroup
-	wg.Add(1)
+	// keepAlive fires once no text has been sent for c.streamKeepAlive.
+	var keepAliveTimer *time.Timer
+	var keepAlive <-chan time.Time
+	if c.streamKeepAlive > 0 {
+		keepAliveTimer = time.NewTimer(c.streamKeepAlive)
+		defer keepAliveTimer.Stop()
+		keepAlive = keepAliveTimer.C
+	}
 
-	// Response watching
-	go func(wg *sync.WaitGroup, errCh chan<- error) {
-		defer wg.Done()
-		for {
+	for {
+		chunk := pending
+		if chunk == nil {
 			select {
+			case <-keepAlive:
+				chunk = &textChunk{Text: " "}
 			case <-ctx.Done():
-				return
-			default:
-				if !driverActive {
-					return
-				}
-				var input StreamingInputResponse
-				var response StreamingOutputResponse
-				if err := conn.ReadJSON(&input); err != nil {
-					if driverActive {
-						errCh <- err
-						driverError = true
-						inputCancel()
-					}
-					return
+				abort()
+				return nil, ctx.Err()
+			case err := <-readErr:
+				// The reader stopped before all the text was sent. If the API ended the session, e.g. with the
+				// final message, the remaining text would be dropped, which is reported rather than a success.
+				if err == nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					err = ErrStreamEndedEarly
 				}
-
-				b, err := base64.StdEncoding.DecodeString(input.Audio)
-				if err != nil {
-					if driverActive {
-						errCh <- err
-						driverError = true
-						inputCancel()
-					}
-					return
+				return nil, err
+			case text, ok := <-textReader:
+				if !ok {
+					return nil, finishInputStream(ctx, conn, readErr, abort)
 				}
-				// Send audio through the pipeline
-				if _, err := AudioResponsePipe.Write(b); err != nil {
-					break
+				chunk = &textChunk{Text: text, TryTriggerGeneration: true}
+			}
+		}
+		if err := conn.WriteJSON(chunk); err != nil {
+			abort()
+			return chunk, err
+		}
+		pending = nil
+		if c.streamIdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(c.streamIdleTimeout))
+		}
+		if keepAliveTimer != nil {
+			if !keepAliveTimer.Stop() {
+				select {
+				case <-keepAliveTimer.C:
+				default:
 				}
+			}
+			keepAliveTimer.Reset(c.streamKeepAlive)
+		}
+	}
+}
 
-				// Send non-audio via the response channel
-				response = StreamingOutputResponse{
-					IsFinal:             input.IsFinal,
-					NormalizedAlignment: input.NormalizedAlignment,
-					Alignment:           input.Alignment,
-				}
-				ResponseChannel <- response
+// finishInputStream sends the empty text message that makes the API flush its buffer, then waits for the
+// remaining audio to be received. The message is only sent if the reader is still running, i.e. the connection
+// is still open.
+func finishInputStream(ctx context.Context, conn *websocket.Conn, readErr <-chan error, abort func()) error {
+	select {
+	case err := <-readErr:
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil
+		}
+		return err
+	default:
+	}
+	if err := conn.WriteJSON(map[string]string{"text": ""}); err != nil {
+		abort()
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		abort()
+		return ctx.Err()
+	case err := <-readErr:
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil
+		}
+		return err
+	}
+}
+
+// readInputStream reads the messages sent by the API over a stream-input connection until the final message
+// is received or an error occurs. Audio is decoded and written to audioWriter, while all other information is
+// sent over responseChan, unless it is nil. If rawAudio is true, the audio is sent over responseChan as
+// received instead, and audioWriter is not used.
+//
+// If idleTimeout is positive, reading fails once no message was received for that long.
+func readInputStream(ctx context.Context, conn *websocket.Conn, responseChan chan<- StreamingOutputResponse, audioWriter io.Writer, rawAudio bool, idleTimeout time.Duration) error {
+	for {
+		if idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		}
+		var input StreamingInputResponse
+		if err := conn.ReadJSON(&input); err != nil {
+			if isTimeout(err) {
+				return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+			}
+			return err
+		}
+
+		if !rawAudio {
+			b, err := base64.StdEncoding.DecodeString(input.Audio)
+			if err != nil {
+				return &streamError{err}
+			}
+			// Send audio through the pipeline
+			if _, err := audioWriter.Write(b); err != nil {
+				return &streamError{err}
+			}
+		}
+
+		// Send non-audio via the response channel
+		if responseChan != nil {
+			response := StreamingOutputResponse{
+				IsFinal:             input.IsFinal,
+				NormalizedAlignment: input.NormalizedAlignment,
+				Alignment:           input.Alignment,
+				Text:                input.Alignment.Text(),
+				Seed:                input.Seed,
+				RequestId:           input.RequestId,
+				NextRequestIds:      input.NextRequestIds,
+			}
+			if rawAudio {
+				response.AudioBase64 = input.Audio
+			}
+			select {
+			case responseChan <- response:
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
-	}(&wg, errCh)
-
-	// Input watching
-InputWatcher:
-	for {
-		select {
-		case <-inputCtx.Done():
-			driverActive = false
-			break InputWatcher
-		case <-ctx.Done():
-			driverActive = false
-			break InputWatcher
-		case chunk, ok := <-TextReader:
-			if !ok || !driverActive {
-				break InputWatcher
-			}
-			ch := &textChunk{Text: chunk, TryTriggerGeneration: true}
-			if err := conn.WriteJSON(ch); err != nil {
-				errCh <- err
-				break InputWatcher
-			}
+
+		if input.IsFinal {
+			return nil
+		}
+	}
+}
+
+// streamError wraps errors occurring while processing received stream data, as opposed to connection errors.
+type streamError struct {
+	err error
+}
+
+func (e *streamError) Error() string { return e.err.Error() }
+
+func (e *streamError) Unwrap() error { return e.err }
+
+// LatencyOptimizations returns a QueryFunc that sets the http query 'optimize_streaming_latency' to
+// a certain value. It is meant to be used used with TextToSpeech and TextToSpeechStream to turn
+// on latency optimization.
+//
+// Possible values:
+// 0 - default mode (no latency optimizations).
+// 1 - normal latency optimizations.
+// 2 - strong latency optimizations.
+// 3 - max latency optimizations.
+// 4 - max latency optimizations, with text normalizer turned off (best latency, but can mispronounce things like numbers or dates).
+func LatencyOptimizations(value int) QueryFunc {
+	return func(q *url.Values) {
+		q.Add("optimize_streaming_latency", fmt.Sprint(value))
+	}
+}
+
+// Output formats accepted by OutputFormat and ValidatedOutputFormat.
+const (
+	FormatMP3_22050_32   = "mp3_22050_32"
+	FormatMP3_44100_32   = "mp3_44100_32"
+	FormatMP3_44100_64   = "mp3_44100_64"
+	FormatMP3_44100_96   = "mp3_44100_96"
+	FormatMP3_44100_128  = "mp3_44100_128"
+	FormatMP3_44100_192  = "mp3_44100_192"
+	FormatPCM_8000       = "pcm_8000"
+	FormatPCM_16000      = "pcm_16000"
+	FormatPCM_22050      = "pcm_22050"
+	FormatPCM_24000      = "pcm_24000"
+	FormatPCM_44100      = "pcm_44100"
+	FormatPCM_48000      = "pcm_48000"
+	FormatULaw_8000      = "ulaw_8000"
+	FormatALaw_8000      = "alaw_8000"
+	FormatOpus_48000_32  = "opus_48000_32"
+	FormatOpus_48000_64  = "opus_48000_64"
+	FormatOpus_48000_96  = "opus_48000_96"
+	FormatOpus_48000_128 = "opus_48000_128"
+	FormatOpus_48000_192 = "opus_48000_192"
+
+	// The wav_* formats are not supported by the API. TextToSpeech and TextToSpeechLong request the pcm_* format
+	// with the same sample rate instead and wrap the audio in a WAV container (see PCMToWAV).
+	FormatWAV_16000 = "wav_16000"
+	FormatWAV_22050 = "wav_22050"
+	FormatWAV_24000 = "wav_24000"
+	FormatWAV_44100 = "wav_44100"
+)
+
+var outputFormats = map[string]bool{
+	FormatMP3_22050_32: true, FormatMP3_44100_32: true, FormatMP3_44100_64: true, FormatMP3_44100_96: true,
+	FormatMP3_44100_128: true, FormatMP3_44100_192: true,
+	FormatPCM_8000: true, FormatPCM_16000: true, FormatPCM_22050: true, FormatPCM_24000: true,
+	FormatPCM_44100: true, FormatPCM_48000: true,
+	FormatULaw_8000: true, FormatALaw_8000: true,
+	FormatOpus_48000_32: true, FormatOpus_48000_64: true, FormatOpus_48000_96: true, FormatOpus_48000_128: true,
+	FormatOpus_48000_192: true,
+	FormatWAV_16000:      true, FormatWAV_22050: true, FormatWAV_24000: true, FormatWAV_44100: true,
+}
+
+// ValidateOutputFormat checks that value is one of the output formats known to this package (see the
+// Format constants).
+//
+// It returns nil if the format is known or an error otherwise.
+func ValidateOutputFormat(value string) error {
+	if !outputFormats[value] {
+		return fmt.Errorf("unknown output format %q", value)
+	}
+	return nil
+}
+
+// ValidatedOutputFormat works like OutputFormat but checks the value with ValidateOutputFormat first, so that
+// typos are caught before any request is sent. OutputFormat can still be used for formats that were added to
+// the API after this package was released.
+//
+// It returns the QueryFunc or an error if the format is unknown.
+func ValidatedOutputFormat(value string) (QueryFunc, error) {
+	if err := ValidateOutputFormat(value); err != nil {
+		return nil, err
+	}
+	return OutputFormat(value), nil
+}
+
+// Endpoints accepted by SupportedOutputFormats.
+const (
+	// EndpointTextToSpeech is the endpoint of TextToSpeech, TextToSpeechLong and TextToSpeechBatch.
+	EndpointTextToSpeech = "text-to-speech"
+	// EndpointTextToSpeechStream is the endpoint of TextToSpeechStream.
+	EndpointTextToSpeechStream = "text-to-speech/stream"
+	// EndpointTextToSpeechInputStream is the endpoint of TextToSpeechInputStream and
+	// TextToSpeechInputStreamReader.
+	EndpointTextToSpeechInputStream = "text-to-speech/stream-input"
+)
+
+// formatOrder lists the output formats in the order SupportedOutputFormats returns them.
+var formatOrder = []string{
+	FormatMP3_22050_32, FormatMP3_44100_32, FormatMP3_44100_64, FormatMP3_44100_96, FormatMP3_44100_128,
+	FormatMP3_44100_192,
+	FormatPCM_8000, FormatPCM_16000, FormatPCM_22050, FormatPCM_24000, FormatPCM_44100, FormatPCM_48000,
+	FormatULaw_8000, FormatALaw_8000,
+	FormatOpus_48000_32, FormatOpus_48000_64, FormatOpus_48000_96, FormatOpus_48000_128, FormatOpus_48000_192,
+	FormatWAV_16000, FormatWAV_22050, FormatWAV_24000, FormatWAV_44100,
+}
+
+// formatMinTiers are the lowest subscription tiers the tier-gated output formats are available to.
+var formatMinTiers = map[string]string{
+	FormatMP3_44100_192: TierCreator,
+	FormatPCM_44100:     TierPro,
+	FormatPCM_48000:     TierPro,
+}
+
+// SupportedOutputFormats returns the output formats (see the Format constants) that can be requested from
+// the given endpoint, one of the Endpoint constants, with a subscription of the given tier, e.g. to populate
+// a list of choices or with ValidateOutputFormatFor. Tier names are normalized as with
+// Subscription.NormalizedTier, and an empty tier returns the formats of all tiers.
+//
+// The wav_* formats are only supported by EndpointTextToSpeech, for which they are emulated. It returns nil
+// for an unknown endpoint.
+func SupportedOutputFormats(endpoint string, tier string) []string {
+	switch endpoint {
+	case EndpointTextToSpeech, EndpointTextToSpeechStream, EndpointTextToSpeechInputStream:
+	default:
+		return nil
+	}
+	sub := Subscription{Tier: tier}
+	var formats []string
+	for _, format := range formatOrder {
+		if strings.HasPrefix(format, "wav_") && endpoint != EndpointTextToSpeech {
+			continue
+		}
+		if minTier, ok := formatMinTiers[format]; ok && tier != "" && !sub.atLeast(minTier) {
+			continue
 		}
+		formats = append(formats, format)
 	}
+	return formats
+}
 
-	// Send final "" to close out TTS buffer
-	if driverActive && !driverError {
-		if err := conn.WriteJSON(map[string]string{"text": ""}); err != nil {
-			if ctx.Err() == nil {
-				errCh <- err
-			}
-		}
+// ValidateOutputFormatFor checks that value is one of the output formats SupportedOutputFormats returns for
+// the endpoint and tier, so that requests for a format that the endpoint or the subscription doesn't support
+// fail before they are sent.
+//
+// It returns nil if the format is supported or an error otherwise.
+func ValidateOutputFormatFor(endpoint, tier, value string) error {
+	if SupportedOutputFormats(endpoint, "") == nil {
+		return fmt.Errorf("unknown endpoint %q", endpoint)
 	}
-	conn.Close()
-	wg.Wait()
-
-	// Errors?
-	select {
-	case readErr := <-errCh:
-		if driverActive || driverError {
-			// Only send if the driver is active or the unexpected error flag is active
-			return readErr
-		} else {
-			return nil
-		}
-	default:
+	if err := ValidateOutputFormat(value); err != nil {
+		return err
+	}
+	if !supportsOutputFormat(endpoint, "", value) {
+		return fmt.Errorf("output format %q is not supported by the %s endpoint", value, endpoint)
+	}
+	if !supportsOutputFormat(endpoint, tier, value) {
+		return fmt.Errorf("output format %q is not available to the %s tier", value, tier)
 	}
-
 	return nil
 }
 
-// LatencyOptimizations returns a QueryFunc that sets the http query 'optimize_streaming_latency' to
-// a certain value. It is meant to be used used with TextToSpeech and TextToSpeechStream to turn
-// on latency optimization.
-//
-// Possible values:
-// 0 - default mode (no latency optimizations).
-// 1 - normal latency optimizations.
-// 2 - strong latency optimizations.
-// 3 - max latency optimizations.
-// 4 - max latency optimizations, with text normalizer turned off (best latency, but can mispronounce things like numbers or dates).
-func LatencyOptimizations(value int) QueryFunc {
-	return func(q *url.Values) {
-		q.Add("optimize_streaming_latency", fmt.Sprint(value))
+// supportsOutputFormat reports whether value is one of the formats SupportedOutputFormats returns.
+func supportsOutputFormat(endpoint, tier, value string) bool {
+	for _, format := range SupportedOutputFormats(endpoint, tier) {
+		if format == value {
+			return true
+		}
 	}
+	return false
 }
 
 // OutputFormat returns a QueryFunc that sets the http query 'output_format' to a certain value.
 // It is meant to be used used with TextToSpeech and TextToSpeechStream to change the output format to
-// a value other than the default (mp3_44100_128).
+// a value other than the default (mp3_44100_128). The value is not validated, see ValidatedOutputFormat and
+// ValidateOutputFormatFor.
 //
-// Possible values:
+// Possible values include:
 // mp3_22050_32 - mp3 with 22.05kHz sample rate at 32kbps.
 // mp3_44100_32 - mp3 with 44.1kHz sample rate at 32kbps.
 // mp3_44100_64 - mp3 with 44.1kHz sample rate at 64kbps.
@@ -376,12 +1539,24 @@ func LatencyOptimizations(value int) QueryFunc {
 // pcm_24000 - PCM (S16LE) with 24kHz sample rate.
 // pcm_44100 - PCM (S16LE) with 44.1kHz sample rate (Requires subscription of Independent Publisher tier or above).
 // ulaw_8000 - μ-law with 8kHz sample rate. Note that this format is commonly used for Twilio audio inputs.
+// wav_16000, wav_22050, wav_24000, wav_44100 - WAV (S16LE), only supported by TextToSpeech and TextToSpeechLong,
+// see the FormatWAV_* constants.
 func OutputFormat(value string) QueryFunc {
 	return func(q *url.Values) {
 		q.Add("output_format", value)
 	}
 }
 
+// EnableLogging returns a QueryFunc that sets the http query 'enable_logging' to a given value. It is meant to be
+// used with the text-to-speech methods, including TextToSpeechInputStream. Passing false enables the zero retention
+// mode of the API, which is only available to enterprise customers: the request and the audio are not stored, so
+// the history of the request isn't available and features relying on it, such as request stitching, don't work.
+func EnableLogging(enabled bool) QueryFunc {
+	return func(q *url.Values) {
+		q.Add("enable_logging", strconv.FormatBool(enabled))
+	}
+}
+
 // WithSettings returns a QueryFunc that sets the http query 'with_settings' to true. It is meant to be used with
 // GetVoice to include Voice setting info with the Voice metadata.
 func WithSettings() QueryFunc {
@@ -391,7 +1566,7 @@ func WithSettings() QueryFunc {
 }
 
 // PageSize returns a QueryFunc that sets the http query 'page_size' to a given value. It is meant to be used
-// with GetHistory to set the number of elements returned in the GetHistoryResponse.History slice.
+// with GetHistory, GetVoicesPaged or GetSharedVoices to set the number of elements returned per page.
 func PageSize(n int) QueryFunc {
 	return func(q *url.Values) {
 		q.Add("page_size", fmt.Sprint(n))
@@ -399,10 +1574,66 @@ func PageSize(n int) QueryFunc {
 }
 
 // StartAfter returns a QueryFunc that sets the http query 'start_after_history_item_id' to a given item ID.
-// It is meant to be used with GetHistory to specify which history item to start with when retrieving history.
+// It is meant to be used with GetHistory to specify which history item to start with when retrieving history,
+// which NextHistoryPageFunc does automatically. A later StartAfter replaces an earlier one.
 func StartAfter(id string) QueryFunc {
 	return func(q *url.Values) {
-		q.Add("start_after_history_item_id", id)
+		q.Set("start_after_history_item_id", id)
+	}
+}
+
+// NextPageToken returns a QueryFunc that sets the http query 'next_page_token' to a given token. It is meant to
+// be used with GetVoicesPaged to specify which page to retrieve, which NextVoicesPageFunc does automatically.
+func NextPageToken(token string) QueryFunc {
+	return func(q *url.Values) {
+		q.Set("next_page_token", token)
+	}
+}
+
+// VoiceCategory returns a QueryFunc that sets the http query 'category' to a given value. It is meant to be used
+// with GetVoices or GetVoicesPaged to only retrieve the voices of a certain category, e.g. "cloned". The filter
+// is applied by the API, where supported, and requests are otherwise answered with all voices. Use
+// GetVoicesByCategory to be sure only the voices of the category are returned.
+func VoiceCategory(category string) QueryFunc {
+	return func(q *url.Values) {
+		q.Set("category", category)
+	}
+}
+
+// Page returns a QueryFunc that sets the http query 'page' to a given value. It is meant to be used with
+// GetSharedVoices to retrieve the pages following the first one, which is page 0.
+func Page(n int) QueryFunc {
+	return func(q *url.Values) {
+		q.Set("page", fmt.Sprint(n))
+	}
+}
+
+// VoiceLanguage returns a QueryFunc that sets the http query 'language' to a given language code, e.g. "en". It
+// is meant to be used with GetSharedVoices to only retrieve the voices of a certain language.
+func VoiceLanguage(code string) QueryFunc {
+	return func(q *url.Values) {
+		q.Set("language", code)
+	}
+}
+
+// UsageBreakdown returns a QueryFunc that sets the http query 'breakdown_type' to a given value. It is meant to be
+// used with GetCharacterUsage to break the usage down by category. Some of the accepted values are:
+// none - no breakdown, the usage is reported under "All" (default).
+// voice - by voice ID.
+// user - by user, for workspaces.
+// api_keys - by API key.
+func UsageBreakdown(breakdownType string) QueryFunc {
+	return func(q *url.Values) {
+		q.Add("breakdown_type", breakdownType)
+	}
+}
+
+// UsageAggregationInterval returns a QueryFunc that sets the http query 'aggregation_interval' to a given value.
+// It is meant to be used with GetCharacterUsage to set the duration of the time buckets. The accepted values are
+// hour, day (default), week, month and cumulative.
+func UsageAggregationInterval(interval string) QueryFunc {
+	return func(q *url.Values) {
+		q.Add("aggregation_interval", interval)
 	}
 }
 
@@ -415,16 +1646,145 @@ func StartAfter(id string) QueryFunc {
 //
 // It returns a byte slice that contains mpeg encoded audio data in case of success, or an error.
 func (c *Client) TextToSpeech(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) ([]byte, error) {
+	audio, _, err := c.TextToSpeechWithContentType(voiceID, ttsReq, queries...)
+	return audio, err
+}
+
+// TextToSpeechWithContentType works like TextToSpeech, but also returns the MIME type of the audio, as reported
+// by the Content-Type header of the response, e.g. "audio/mpeg". It depends on the requested output format and
+// can be used as is by web handlers serving the audio. For the wav_* formats, it is "audio/wav".
+//
+// It returns a byte slice that contains the audio data and its MIME type in case of success, or an error.
+func (c *Client) TextToSpeechWithContentType(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) ([]byte, string, error) {
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
+	if err != nil {
+		return nil, "", err
+	}
 	reqBody, err := json.Marshal(ttsReq)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	queries, wavSampleRate, wav := wavOutput(queries)
 	b := bytes.Buffer{}
-	err = c.doRequest(c.ctx, &b, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, queries...)
+	header, err := c.doRequestWithHeader(c.ctx, &b, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, false, nil, queries...)
+	if err != nil {
+		return nil, "", err
+	}
+	if wav {
+		return PCMToWAV(b.Bytes(), wavSampleRate), "audio/wav", nil
+	}
+	return b.Bytes(), header.Get("Content-Type"), nil
+}
+
+// BuildTextToSpeechRequest prepares, without sending, the request that TextToSpeech would send.
+//
+// It takes the same arguments as TextToSpeech and returns the prepared request, with its URL, headers and body set,
+// or an error. The request can be inspected, signed or forwarded and sent with any http.Client. For the wav_*
+// formats, the request is for the pcm_* format with the same sample rate, which PCMToWAV turns into WAV audio.
+func (c *Client) BuildTextToSpeechRequest(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (*http.Request, error) {
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
 	if err != nil {
 		return nil, err
 	}
-	return b.Bytes(), nil
+	reqBody, err := json.Marshal(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+	queries, _, _ = wavOutput(queries)
+	return c.buildRequest(http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s", c.baseURL, voiceID), reqBody, contentTypeJSON, queries...)
+}
+
+// TextToSpeechLong converts and returns a given text that may exceed the model's per-request character
+// limit to speech audio using a certain voice.
+//
+// It takes a string argument that represents the ID of the voice to be used, a string argument containing
+// the text to be converted, a TextToSpeechRequest argument whose settings (model, voice settings) are applied
+// to every generated segment, an int argument representing the maximum number of characters sent per request
+// and an optional list of QueryFunc 'queries' to modify the requests.
+//
+// The text is split on sentence boundaries into segments of at most maxChars characters. Sentences that are
+// longer than maxChars are split between words. The segments are converted one after the other and each request
+// is sent with the surrounding text and the IDs of the previous requests, so that the prosody is kept consistent
+// across segments. The Text, PreviousText, NextText and PreviousRequestIds fields of ttsReq are overwritten.
+//
+// It returns a byte slice containing the audio of all segments concatenated, or an error. Concatenation is only
+// meaningful for formats that can be joined as is, such as mp3 and pcm.
+func (c *Client) TextToSpeechLong(voiceID string, text string, ttsReq TextToSpeechRequest, maxChars int, queries ...QueryFunc) ([]byte, error) {
+	if maxChars <= 0 {
+		return nil, fmt.Errorf("maxChars must be positive, got %d", maxChars)
+	}
+	ttsReq.Text = text
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+	text = ttsReq.Text
+
+	queries, wavSampleRate, wav := wavOutput(queries)
+	segments := splitText(text, maxChars)
+	audio := bytes.Buffer{}
+	var requestIds []string
+	for i, segment := range segments {
+		segReq := ttsReq
+		segReq.Text = segment
+		segReq.PreviousText, segReq.NextText = "", ""
+		if i > 0 {
+			segReq.PreviousText = segments[i-1]
+		}
+		if i < len(segments)-1 {
+			segReq.NextText = segments[i+1]
+		}
+		// The API accepts up to 3 previous request IDs.
+		segReq.PreviousRequestIds = requestIds
+		if len(requestIds) > 3 {
+			segReq.PreviousRequestIds = requestIds[len(requestIds)-3:]
+		}
+
+		reqBody, err := json.Marshal(segReq)
+		if err != nil {
+			return nil, err
+		}
+		header, err := c.doRequestWithHeader(c.ctx, &audio, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, false, nil, queries...)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d of %d: %w", i+1, len(segments), err)
+		}
+		if id := header.Get("request-id"); id != "" {
+			requestIds = append(requestIds, id)
+		}
+	}
+	if wav {
+		return PCMToWAV(audio.Bytes(), wavSampleRate), nil
+	}
+	return audio.Bytes(), nil
+}
+
+// TextToSpeechBatch converts many texts to speech audio using a certain voice, with several requests in flight
+// at once, e.g. to generate a set of short prompts.
+//
+// It takes the ID of the voice, the texts, a TextToSpeechRequest argument that is used as the template of the
+// request for each text, the maximum number of concurrent requests (at least 1 is used) and an optional list of
+// QueryFunc 'queries' to modify the requests. Each text is converted with TextToSpeech. No new conversions are
+// started once the client's context is done or, with WithBatchFailFast, once a conversion failed.
+//
+// It returns a slice with the audio of each text, in the same order as the texts, and an error combining the
+// errors of the texts that failed to be converted, if any. The audio of texts that failed or were not converted
+// is nil.
+func (c *Client) TextToSpeechBatch(voiceID string, texts []string, ttsReq TextToSpeechRequest, concurrency int, queries ...QueryFunc) ([][]byte, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	audio := make([][]byte, len(texts))
+	err := c.forEachConcurrently(len(texts), concurrency, c.batchFailFast, func(i int) error {
+		req := ttsReq
+		req.Text = texts[i]
+		b, err := c.TextToSpeech(voiceID, req, queries...)
+		if err != nil {
+			return fmt.Errorf("text %d: %w", i, err)
+		}
+		audio[i] = b
+		return nil
+	})
+	return audio, err
 }
 
 // TextToSpeechStream converts and streams a given text to speech audio using a certain voice.
@@ -434,16 +1794,62 @@ func (c *Client) TextToSpeech(voiceID string, ttsReq TextToSpeechRequest, querie
 // to be used to generate the audio alongside other settings and an optional list of QueryFunc 'queries' to modify the
 // request. The QueryFunc functions relevant for this method are LatencyOptimizations and OutputFormat.
 //
-// It is important to set the timeout of the client to a duration large enough to maintain the desired streaming period.
+// The timeout of the client only applies until the first bytes of audio are received, so the stream itself can last
+// longer than the timeout.
 //
-// It returns nil if successful or an error otherwise.
+// It returns nil if successful or an error otherwise. If the client's context is canceled or its deadline
+// passes, the context's error, i.e. context.Canceled or context.DeadlineExceeded, is returned as is.
 func (c *Client) TextToSpeechStream(streamWriter io.Writer, voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) error {
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
+	if err != nil {
+		return err
+	}
 	reqBody, err := json.Marshal(ttsReq)
 	if err != nil {
 		return err
 	}
 
-	return c.doRequest(c.ctx, streamWriter, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s/stream", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, queries...)
+	return c.doStreamRequest(c.ctx, streamWriter, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s/stream", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, queries...)
+}
+
+// BuildTextToSpeechStreamRequest prepares, without sending, the request that TextToSpeechStream would send.
+//
+// It takes the same arguments as TextToSpeechStream, except for the writer, and returns the prepared request
+// or an error. The wav_* formats are rejected, as they are only supported by TextToSpeech.
+func (c *Client) BuildTextToSpeechStreamRequest(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (*http.Request, error) {
+	if _, sampleRate, wav := wavOutput(queries); wav {
+		return nil, fmt.Errorf("output format \"wav_%d\" is not supported by the %s endpoint", sampleRate, EndpointTextToSpeechStream)
+	}
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+	reqBody, err := json.Marshal(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildRequest(http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s/stream", c.baseURL, voiceID), reqBody, contentTypeJSON, queries...)
+}
+
+// TextToSpeechPipe works like TextToSpeechStream, but returns the audio as an io.ReadCloser that produces it as
+// it is generated, for consumers that read audio rather than have it written to them, such as an audio player
+// or an upload.
+//
+// It takes the same arguments as TextToSpeechStream, except for the writer. It returns once the first bytes of
+// audio are received, so that errors from the API are returned rather than surfacing when reading. Errors that
+// occur later on, e.g. when the stream is interrupted, are returned by Read. The reader must be closed to release
+// the connection, and closing it before the end aborts the conversion.
+func (c *Client) TextToSpeechPipe(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (io.ReadCloser, error) {
+	ttsReq, err := c.prepareTextToSpeech(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+	reqBody, err := json.Marshal(ttsReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doReaderRequest(c.ctx, http.MethodPost, fmt.Sprintf("%s/text-to-speech/%s/stream", c.baseURL, voiceID), bytes.NewBuffer(reqBody), contentTypeJSON, queries...)
 }
 
 // TextToSpeechInputStream converts and returns a given text to speech audio using a certain voice.
@@ -452,9 +1858,72 @@ func (c *Client) TextToSpeechStream(streamWriter io.Writer, voiceID string, ttsR
 // the audio data will be written, a string argument that represents the ID of the voice to be used for the text to
 // speech conversion, a modelID string argument that represents the ID of the model to be used for the conversion,
 // a TextToSpeechInputStreamingRequest argument that contains the settings for the conversion and
-// an optional list of QueryFunc 'queries' to modify the request.
+// an optional list of QueryFunc 'queries' to modify the request. The QueryFunc functions relevant for this method
+// are LatencyOptimizations, OutputFormat and EnableLogging; conflicting or unsupported values are rejected before
+// connecting.
 func (c *Client) TextToSpeechInputStream(textReader chan string, responseChan chan StreamingOutputResponse, AudioResponsePipe io.Writer, voiceID string, modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) error {
-	return c.doInputStreamingRequest(c.ctx, textReader, responseChan, AudioResponsePipe, fmt.Sprintf("%s/text-to-speech/%s/stream-input?model_id=%s", c.baseWSUrl, voiceID, modelID), ttsReq, contentTypeJSON, queries...)
+	queries = append([]QueryFunc{modelIDQuery(modelID)}, queries...)
+	return c.doInputStreamingRequest(c.ctx, textReader, responseChan, AudioResponsePipe, fmt.Sprintf("%s/text-to-speech/%s/stream-input", c.baseWSUrl, voiceID), ttsReq, contentTypeJSON, queries...)
+}
+
+// TextToSpeechInputStreamReader converts the text read from an io.Reader to speech audio as it is read, using the
+// stream-input API like TextToSpeechInputStream, without the need to manage the text and response channels.
+//
+// It takes an io.Reader argument from which the text is read, which is split into chunks as with NewTextChunker
+// (see WithStreamCoalesce), an io.Writer argument to which the audio data is written, the IDs of the voice and
+// model to be used, a TextToSpeechInputStreamingRequest argument that contains the settings for the conversion
+// and an optional list of QueryFunc 'queries' to modify the request.
+//
+// It returns nil once all the text was converted, or an error. If an error occurs before the end of the text,
+// the rest of it is still read from r, in the background, and discarded.
+func (c *Client) TextToSpeechInputStreamReader(r io.Reader, audioOut io.Writer, voiceID, modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) error {
+	chunks := newTextChunker(r, ChunkerConfig{CoalesceChars: c.streamCoalesce})
+	err := c.TextToSpeechInputStream(chunks, nil, audioOut, voiceID, modelID, ttsReq, queries...)
+	// Drain the chunks that weren't consumed, so that the goroutines reading r can exit.
+	go func() {
+		for range chunks {
+		}
+	}()
+	return err
+}
+
+// validateInputStreamQuery checks the queries of a stream-input request, so that combinations the endpoint
+// rejects, or accepts but doesn't behave as expected with, fail before the connection is established:
+//   - output_format, optimize_streaming_latency and enable_logging set more than once, e.g. by passing
+//     OutputFormat twice, as the API only considers one of the values;
+//   - the formats SupportedOutputFormats doesn't return for EndpointTextToSpeechInputStream, such as the wav_*
+//     formats, which TextToSpeech and TextToSpeechLong emulate but the stream-input endpoint can't;
+//   - an optimize_streaming_latency outside of 0 to 4, or an enable_logging other than true or false.
+//
+// Output formats that are unknown to this package are let through, see ValidatedOutputFormat.
+func validateInputStreamQuery(q url.Values) error {
+	for _, key := range []string{"output_format", "optimize_streaming_latency", "enable_logging"} {
+		if vals := q[key]; len(vals) > 1 {
+			return fmt.Errorf("conflicting values for query %s: %q", key, vals)
+		}
+	}
+	if format := q.Get("output_format"); outputFormats[format] && !supportsOutputFormat(EndpointTextToSpeechInputStream, "", format) {
+		return fmt.Errorf("output format %q is not supported by the stream-input API, use the pcm_* format with the same sample rate", format)
+	}
+	if latency, ok := q["optimize_streaming_latency"]; ok {
+		if n, err := strconv.Atoi(latency[0]); err != nil || n < 0 || n > 4 {
+			return fmt.Errorf("invalid optimize_streaming_latency %q, must be between 0 and 4", latency[0])
+		}
+	}
+	if logging, ok := q["enable_logging"]; ok && logging[0] != "true" && logging[0] != "false" {
+		return fmt.Errorf("invalid enable_logging %q, must be true or false", logging[0])
+	}
+	return nil
+}
+
+// modelIDQuery returns a QueryFunc that sets the 'model_id' query of the stream-input endpoint, unless
+// modelID is empty.
+func modelIDQuery(modelID string) QueryFunc {
+	return func(q *url.Values) {
+		if modelID != "" {
+			q.Set("model_id", modelID)
+		}
+	}
 }
 
 // GetModels retrieves the list of all available models.
@@ -462,7 +1931,7 @@ func (c *Client) TextToSpeechInputStream(textReader chan string, responseChan ch
 // It returns a slice of Model objects or an error.
 func (c *Client) GetModels() ([]Model, error) {
 	b := bytes.Buffer{}
-	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/models", c.baseURL), &bytes.Buffer{}, contentTypeJSON)
+	err := c.doConditionalRequest(c.ctx, &b, fmt.Sprintf("%s/models", c.baseURL))
 	if err != nil {
 		return nil, err
 	}
@@ -475,12 +1944,78 @@ func (c *Client) GetModels() ([]Model, error) {
 	return models, nil
 }
 
+// BuildGetModelsRequest prepares, without sending, the request that GetModels would send.
+//
+// It returns the prepared request or an error.
+func (c *Client) BuildGetModelsRequest() (*http.Request, error) {
+	return c.buildRequest(http.MethodGet, fmt.Sprintf("%s/models", c.baseURL), nil, contentTypeJSON)
+}
+
+// ValidateLanguageForModel checks whether a model supports a certain language.
+//
+// It takes two string arguments representing the ID of the model and the language code (e.g. "en") respectively.
+// The list of models is retrieved with GetModels the first time it is needed and cached for the lifetime of the
+// client.
+//
+// It returns nil if the language is supported, or an error if it isn't, the model is unknown or the models could
+// not be retrieved.
+func (c *Client) ValidateLanguageForModel(modelID, langCode string) error {
+	model, err := c.cachedModel(modelID)
+	if err != nil {
+		return err
+	}
+	if !model.SupportsLanguage(langCode) {
+		return fmt.Errorf("model %q does not support language %q", modelID, langCode)
+	}
+	return nil
+}
+
+// WillExceedLimit checks whether text is too long to be sent in a single text-to-speech request to a model.
+//
+// It takes the ID of the model and the text. Characters are counted as runes, not bytes, so multibyte text
+// is measured the way the API counts it. The limit is the model's limit for subscribed users, as reported by
+// Model.MaxChars, and the list of models is cached the same way as for ValidateLanguageForModel. If the API
+// doesn't report a limit for the model, the text is never considered too long.
+//
+// It returns whether the limit would be exceeded and by how many characters, or an error if the model is
+// unknown or the models could not be retrieved.
+func (c *Client) WillExceedLimit(modelID, text string) (bool, int, error) {
+	model, err := c.cachedModel(modelID)
+	if err != nil {
+		return false, 0, err
+	}
+	limit := model.MaxChars(true)
+	if limit <= 0 {
+		return false, 0, nil
+	}
+	if over := utf8.RuneCountInString(text) - limit; over > 0 {
+		return true, over, nil
+	}
+	return false, 0, nil
+}
+
+// cachedModel returns the model with the given ID from the client's model cache.
+func (c *Client) cachedModel(modelID string) (Model, error) {
+	models, err := c.models.get(c.GetModels)
+	if err != nil {
+		return Model{}, err
+	}
+	for _, m := range models {
+		if m.ModelId == modelID {
+			return m, nil
+		}
+	}
+	return Model{}, fmt.Errorf("unknown model %q", modelID)
+}
+
 // GetVoices retrieves the list of all voices available for use.
 //
+// It takes an optional list of QueryFunc 'queries', e.g. VoiceCategory to filter the voices server-side.
+//
 // It returns a slice of Voice objects or an error.
-func (c *Client) GetVoices() ([]Voice, error) {
+func (c *Client) GetVoices(queries ...QueryFunc) ([]Voice, error) {
 	b := bytes.Buffer{}
-	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/voices", c.baseURL), &bytes.Buffer{}, contentTypeJSON)
+	err := c.doConditionalRequest(c.ctx, &b, fmt.Sprintf("%s/voices", c.baseURL), queries...)
 	if err != nil {
 		return nil, err
 	}
@@ -493,10 +2028,138 @@ func (c *Client) GetVoices() ([]Voice, error) {
 	return voiceResp.Voices, nil
 }
 
+// BuildGetVoicesRequest prepares, without sending, the request that GetVoices would send.
+//
+// It takes the same optional list of QueryFunc 'queries' as GetVoices and returns the prepared request or an error.
+func (c *Client) BuildGetVoicesRequest(queries ...QueryFunc) (*http.Request, error) {
+	return c.buildRequest(http.MethodGet, fmt.Sprintf("%s/voices", c.baseURL), nil, contentTypeJSON, queries...)
+}
+
+// NextVoicesPageFunc represent functions that can be used to access subsequent pages of voices. It is returned
+// by the GetVoicesPaged client method and works like NextHistoryPageFunc.
+type NextVoicesPageFunc func(...QueryFunc) (GetVoicesResponse, NextVoicesPageFunc, error)
+
+// GetVoicesPaged retrieves the voices available for use one page at a time, which keeps responses small for
+// accounts with thousands of voices.
+//
+// It takes an optional list of QueryFunc 'queries', e.g. PageSize to set the number of voices per page.
+// If the API doesn't paginate the voices, all of them are returned in the first page.
+//
+// It returns a GetVoicesResponse object containing the voices, a function of type NextVoicesPageFunc to retrieve
+// the next page of voices, which is nil for the last page, and an error.
+func (c *Client) GetVoicesPaged(queries ...QueryFunc) (GetVoicesResponse, NextVoicesPageFunc, error) {
+	var voicesResp GetVoicesResponse
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/voices", c.baseURL), &bytes.Buffer{}, contentTypeJSON, queries...)
+	if err != nil {
+		return GetVoicesResponse{}, nil, err
+	}
+
+	if err := json.Unmarshal(b.Bytes(), &voicesResp); err != nil {
+		return GetVoicesResponse{}, nil, err
+	}
+
+	if !voicesResp.HasMore || voicesResp.NextPageToken == "" {
+		return voicesResp, nil, nil
+	}
+
+	nextPageFunc := func(qf ...QueryFunc) (GetVoicesResponse, NextVoicesPageFunc, error) {
+		next := make([]QueryFunc, 0, len(queries)+len(qf)+1)
+		next = append(append(append(next, queries...), qf...), NextPageToken(voicesResp.NextPageToken))
+		return c.GetVoicesPaged(next...)
+	}
+	return voicesResp, nextPageFunc, nil
+}
+
+// GetVoicesByCategory retrieves the list of all voices available for use and returns those that belong to
+// a certain category.
+//
+// It takes a string argument that represents the category (e.g. "premade", "cloned", "generated" or
+// "professional"), which is matched case-insensitively. The category is sent with VoiceCategory so that the
+// API can filter the voices, and the voices are filtered again in case it doesn't.
+//
+// It returns a slice of Voice objects or an error.
+func (c *Client) GetVoicesByCategory(category string) ([]Voice, error) {
+	voices, err := c.GetVoices(VoiceCategory(strings.ToLower(category)))
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []Voice
+	for _, v := range voices {
+		if strings.EqualFold(v.Category, category) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// GetSharedVoices retrieves a page of the voices shared by other users in the voice library.
+//
+// It takes an optional list of QueryFunc 'queries' to modify the request. The QueryFunc functions relevant for
+// this method are PageSize, Page, VoiceCategory and VoiceLanguage. The responses are reused, for the same
+// queries, if the client was configured with WithConditionalRequests, which makes browsing the library cheaper.
+//
+// It returns a GetSharedVoicesResponse object, whose HasMore field reports whether more voices are available on
+// the next page, or an error. SharedVoicesByLanguage and SharedVoicesByCategory can group the returned voices.
+func (c *Client) GetSharedVoices(queries ...QueryFunc) (GetSharedVoicesResponse, error) {
+	b := bytes.Buffer{}
+	err := c.doConditionalRequest(c.ctx, &b, fmt.Sprintf("%s/shared-voices", c.baseURL), queries...)
+	if err != nil {
+		return GetSharedVoicesResponse{}, err
+	}
+
+	var sharedResp GetSharedVoicesResponse
+	if err := json.Unmarshal(b.Bytes(), &sharedResp); err != nil {
+		return GetSharedVoicesResponse{}, err
+	}
+
+	return sharedResp, nil
+}
+
+// FindVoiceByName retrieves the list of all voices available for use and looks up a voice by its name.
+//
+// It takes a string argument that represents the name of the voice, which is matched case-insensitively.
+//
+// It returns the first matching Voice and true if found, false if no voice has the given name, or an error.
+func (c *Client) FindVoiceByName(name string) (Voice, bool, error) {
+	voices, err := c.GetVoices()
+	if err != nil {
+		return Voice{}, false, err
+	}
+
+	for _, v := range voices {
+		if strings.EqualFold(v.Name, name) {
+			return v, true, nil
+		}
+	}
+	return Voice{}, false, nil
+}
+
 // GetDefaultVoiceSettings retrieves the default settings for voices
 //
+// If the client was configured with WithDefaultVoiceSettingsCache, the settings are only retrieved the first
+// time and then returned from the cache.
+//
 // It returns a VoiceSettings object or an error.
 func (c *Client) GetDefaultVoiceSettings() (VoiceSettings, error) {
+	if c.cacheDefaultSettings {
+		return c.defaultSettings.get(c.fetchDefaultVoiceSettings)
+	}
+	return c.fetchDefaultVoiceSettings()
+}
+
+// RefreshDefaultVoiceSettings retrieves the default settings for voices, like GetDefaultVoiceSettings, bypassing
+// and replacing the cached settings of clients configured with WithDefaultVoiceSettingsCache.
+//
+// It returns a VoiceSettings object or an error.
+func (c *Client) RefreshDefaultVoiceSettings() (VoiceSettings, error) {
+	c.defaultSettings.reset()
+	return c.GetDefaultVoiceSettings()
+}
+
+// fetchDefaultVoiceSettings retrieves the default settings for voices from the API.
+func (c *Client) fetchDefaultVoiceSettings() (VoiceSettings, error) {
 	var voiceSettings VoiceSettings
 	b := bytes.Buffer{}
 	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/voices/settings/default", c.baseURL), &bytes.Buffer{}, contentTypeJSON)
@@ -546,11 +2209,61 @@ func (c *Client) GetVoice(voiceId string, queries ...QueryFunc) (Voice, error) {
 		return Voice{}, err
 	}
 
-	if err := json.Unmarshal(b.Bytes(), &voice); err != nil {
-		return Voice{}, err
+	if err := json.Unmarshal(b.Bytes(), &voice); err != nil {
+		return Voice{}, err
+	}
+
+	return voice, nil
+}
+
+// GetVoiceFineTuningStatus retrieves the fine-tuning status of a professional voice clone, which includes the
+// verification attempts and the state of the fine-tuning for every model. It can be polled to follow the
+// training of the voice.
+//
+// It takes a string argument that represents the ID of the voice.
+//
+// It returns a FineTuningStatus object or an error.
+func (c *Client) GetVoiceFineTuningStatus(voiceId string) (FineTuningStatus, error) {
+	voice, err := c.GetVoice(voiceId)
+	if err != nil {
+		return FineTuningStatus{}, err
+	}
+	return voice.FineTuning, nil
+}
+
+// GetVoicePreview downloads the preview audio of a certain voice.
+//
+// It takes a string argument that represents the ID of the voice whose preview is downloaded. The voice is
+// retrieved first and its preview is then downloaded from its PreviewUrl. As the preview is not hosted by the
+// API, the API key is not sent with the download request.
+//
+// It returns a byte slice that contains the mpeg encoded preview audio, or an error. ErrNoPreview is returned
+// if the voice has no preview.
+func (c *Client) GetVoicePreview(voiceId string) ([]byte, error) {
+	voice, err := c.GetVoice(voiceId)
+	if err != nil {
+		return nil, err
+	}
+	if voice.PreviewUrl == "" {
+		return nil, ErrNoPreview
+	}
+
+	_, timeout := c.settings()
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, voice.PreviewUrl, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	return voice, nil
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UnexpectedStatusError{HTTPStatus: resp.StatusCode}
+	}
+	return io.ReadAll(resp.Body)
 }
 
 // DeleteVoice deletes a voice.
@@ -565,9 +2278,11 @@ func (c *Client) DeleteVoice(voiceId string) error {
 // EditVoiceSettings updates the settings for a specific voice.
 //
 // It takes a string argument that represents the ID of the voice to which the settings to be
-// updated belong, and a VoiceSettings argument that contains the new settings to be applied.
+// updated belong, and a VoiceSettings argument that contains the new settings to be applied. Settings
+// left unset are not sent.
 //
-// It returns nil if successful or an error otherwise.
+// It returns nil if successful or an error otherwise. Use EditVoiceSettingsWithResult to also get the settings
+// of the voice after the edit.
 func (c *Client) EditVoiceSettings(voiceId string, settings VoiceSettings) error {
 	reqBody, err := json.Marshal(settings)
 	if err != nil {
@@ -577,26 +2292,137 @@ func (c *Client) EditVoiceSettings(voiceId string, settings VoiceSettings) error
 	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/voices/%s/settings/edit", c.baseURL, voiceId), bytes.NewBuffer(reqBody), contentTypeJSON)
 }
 
+// EditVoiceSettingsWithResult works like EditVoiceSettings, but also returns the settings of the voice after the
+// edit, which spares a call to GetVoiceSettings, e.g. to display them.
+//
+// The settings the API returns in response to the edit are returned. As it may return none, e.g. only a status,
+// the given settings are returned in place of those missing from the response.
+func (c *Client) EditVoiceSettingsWithResult(voiceId string, settings VoiceSettings) (VoiceSettings, error) {
+	reqBody, err := json.Marshal(settings)
+	if err != nil {
+		return VoiceSettings{}, err
+	}
+
+	b := bytes.Buffer{}
+	err = c.doRequest(c.ctx, &b, http.MethodPost, fmt.Sprintf("%s/voices/%s/settings/edit", c.baseURL, voiceId), bytes.NewBuffer(reqBody), contentTypeJSON)
+	if err != nil {
+		return VoiceSettings{}, err
+	}
+	if len(bytes.TrimSpace(b.Bytes())) == 0 {
+		return settings, nil
+	}
+	// The response is decoded into empty settings rather than into settings, whose pointers are shared with the
+	// caller.
+	var result VoiceSettings
+	if err := json.Unmarshal(b.Bytes(), &result); err != nil {
+		return VoiceSettings{}, err
+	}
+	if result.SimilarityBoost == nil {
+		result.SimilarityBoost = settings.SimilarityBoost
+	}
+	if result.Stability == nil {
+		result.Stability = settings.Stability
+	}
+	if result.Style == nil {
+		result.Style = settings.Style
+	}
+	if result.SpeakerBoost == nil {
+		result.SpeakerBoost = settings.SpeakerBoost
+	}
+	return result, nil
+}
+
+// PatchVoiceSettings changes some of the settings of a specific voice and keeps the others as they are.
+//
+// It takes a string argument that represents the ID of the voice and a function that is called with the current
+// settings of the voice, as retrieved with GetVoiceSettings, to modify them. The settings are read and written in
+// separate requests, so concurrent changes to the same voice may be lost.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) PatchVoiceSettings(voiceId string, fn func(*VoiceSettings)) error {
+	settings, err := c.GetVoiceSettings(voiceId)
+	if err != nil {
+		return err
+	}
+	fn(&settings)
+	reqBody, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/voices/%s/settings/edit", c.baseURL, voiceId), bytes.NewBuffer(reqBody), contentTypeJSON)
+}
+
 // AddVoice adds a new voice to the user's VoiceLab.
 //
-// It takes an AddEditVoiceRequest argument that contains the information of the voice to be added.
+// It takes an AddEditVoiceRequest argument that contains the information of the voice to be added. The voice is
+// an instant voice clone of the uploaded samples: the endpoint has no clone type to choose from, as professional
+// voice clones, which require a Creator subscription or above, are created and trained through a separate
+// process, with identity verification.
 //
-// It returns the ID of the newly added voice, or an error.
+// It returns the ID of the newly added voice, or an error. Use AddVoiceFull to also find out whether the
+// voice requires verification.
 func (c *Client) AddVoice(voiceReq AddEditVoiceRequest) (string, error) {
-	reqBodyBuf, contentType, err := voiceReq.buildRequestBody()
+	voiceResp, err := c.AddVoiceFull(voiceReq)
 	if err != nil {
 		return "", err
 	}
+	return voiceResp.VoiceId, nil
+}
+
+// AddVoiceFull works like AddVoice but returns the full response of the API, which includes whether the
+// voice requires verification before it can be used.
+//
+// It returns an AddVoiceResponse object, or an error.
+func (c *Client) AddVoiceFull(voiceReq AddEditVoiceRequest) (AddVoiceResponse, error) {
+	reqBodyBuf, contentType, err := voiceReq.buildRequestBody()
+	if err != nil {
+		return AddVoiceResponse{}, err
+	}
 	b := bytes.Buffer{}
 	err = c.doRequest(c.ctx, &b, http.MethodPost, fmt.Sprintf("%s/voices/add", c.baseURL), reqBodyBuf, contentType)
 	if err != nil {
-		return "", err
+		return AddVoiceResponse{}, err
 	}
 	var voiceResp AddVoiceResponse
 	if err := json.Unmarshal(b.Bytes(), &voiceResp); err != nil {
-		return "", err
+		return AddVoiceResponse{}, err
 	}
-	return voiceResp.VoiceId, nil
+	return voiceResp, nil
+}
+
+// AddVoiceIdempotent works like AddVoice but guards against adding the same voice twice when the request
+// times out, in which case the voice may or may not have been added.
+//
+// The API has no idempotency mechanism for adding voices, so after a timeout the voices of the user are
+// looked up by name (see FindVoiceByName). If a voice with the requested name exists, its ID is returned,
+// otherwise the request is retried once. This has the following limitations:
+//   - Voice names are not unique, so a voice that had the same name before the call is returned as if it
+//     had just been added. Callers should use names that are unique to the request.
+//   - A voice that is still being added when the lookup happens is not found, which results in a duplicate.
+//   - Failures other than timeouts are returned as is and are not retried.
+//
+// It returns the ID of the added or found voice, or an error.
+func (c *Client) AddVoiceIdempotent(voiceReq AddEditVoiceRequest) (string, error) {
+	id, err := c.AddVoice(voiceReq)
+	if err == nil || !isTimeout(err) || c.ctx.Err() != nil {
+		return id, err
+	}
+
+	voice, found, findErr := c.FindVoiceByName(voiceReq.Name)
+	if findErr != nil {
+		return "", fmt.Errorf("looking up voice %q after %w: %v", voiceReq.Name, err, findErr)
+	}
+	if found {
+		return voice.VoiceId, nil
+	}
+	return c.AddVoice(voiceReq)
+}
+
+// isTimeout reports whether err is caused by a request timing out.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
 }
 
 // EditVoice updates an existing voice belonging to the user.
@@ -613,12 +2439,33 @@ func (c *Client) EditVoice(voiceId string, voiceReq AddEditVoiceRequest) error {
 	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/voices/%s/edit", c.baseURL, voiceId), reqBodyBuf, contentType)
 }
 
+// EditVoiceMetadata updates the name, description and labels of an existing voice belonging to the user,
+// without uploading samples, so that the existing samples aren't processed again.
+//
+// It takes a string argument that represents the ID of the voice to update, its name, which the API requires
+// even if unchanged, its description and its labels. The description is left unchanged if empty, and so are
+// the labels if nil.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) EditVoiceMetadata(voiceId string, name, description string, labels map[string]string) error {
+	reqBodyBuf, contentType, err := buildVoiceMetadataBody(name, description, labels)
+	if err != nil {
+		return err
+	}
+	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/voices/%s/edit", c.baseURL, voiceId), reqBodyBuf, contentType)
+}
+
 // DeleteSample deletes a sample associated with a specific voice.
 //
 // It takes two string arguments representing the ID of the voice to which the sample belongs
 // and the ID of the sample to be deleted respectively.
 //
-// It returns nil if successful or an error otherwise.
+// The API provides no way to replace the audio of an existing sample. To replace a sample, delete it and
+// upload the new audio with EditVoice, whose FilePaths are added to the voice as new samples. The new sample
+// gets a new ID.
+//
+// It returns nil if successful or an error otherwise. If the voice or sample doesn't exist, the error
+// matches ErrNotFound.
 func (c *Client) DeleteSample(voiceId, sampleId string) error {
 	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodDelete, fmt.Sprintf("%s/voices/%s/samples/%s", c.baseURL, voiceId, sampleId), &bytes.Buffer{}, contentTypeJSON)
 }
@@ -637,6 +2484,93 @@ func (c *Client) GetSampleAudio(voiceId, sampleId string) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// AddHistoryItemAsSample adds the audio of a history item to a voice as a new sample, e.g. to refine a voice with
+// one of its good outputs.
+//
+// It takes two string arguments representing the ID of the voice and the ID of the history item respectively.
+//
+// The API has no endpoint for this, so the audio is downloaded as with GetHistoryItemAudio and uploaded with the
+// same request as EditVoice, which requires the voice's name to be sent along and is retrieved first with
+// GetVoice. The sample is named after the history item, and the other metadata of the voice is left unchanged.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) AddHistoryItemAsSample(voiceId, historyItemId string) error {
+	voice, err := c.GetVoice(voiceId)
+	if err != nil {
+		return err
+	}
+
+	b := bytes.Buffer{}
+	header, err := c.doRequestWithHeader(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/history/%s/audio", c.baseURL, historyItemId), &bytes.Buffer{}, contentTypeJSON, false, nil)
+	if err != nil {
+		return err
+	}
+	contentType := header.Get("Content-Type")
+	fileName := historyItemId
+	switch mediaType, _, _ := mime.ParseMediaType(contentType); mediaType {
+	case "audio/mpeg":
+		fileName += ".mp3"
+	case "audio/wav", "audio/x-wav":
+		fileName += ".wav"
+	}
+
+	reqBodyBuf, reqContentType, err := buildSampleUploadBody(voice.Name, fileName, contentType, b.Bytes())
+	if err != nil {
+		return err
+	}
+	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/voices/%s/edit", c.baseURL, voiceId), reqBodyBuf, reqContentType)
+}
+
+// GetVoiceSamples retrieves the samples of a certain voice, in the order they were uploaded.
+//
+// It takes a string argument that represents the ID of the voice. The samples are part of the voice's metadata,
+// which is retrieved with GetVoice.
+//
+// It returns a slice of VoiceSample objects, which is empty for voices without samples such as premade voices,
+// or an error.
+func (c *Client) GetVoiceSamples(voiceId string) ([]VoiceSample, error) {
+	voice, err := c.GetVoice(voiceId)
+	if err != nil {
+		return nil, err
+	}
+	return voice.Samples, nil
+}
+
+// DownloadVoiceSamples downloads the audio of all samples of a certain voice and packs them into a zip file.
+//
+// It takes a string argument that represents the ID of the voice. Each sample is stored in the zip file under
+// its original file name prefixed with its position, e.g. "01_sample.mp3", so that samples uploaded with the
+// same name don't overwrite each other. The audio of each sample is copied into the zip file as it is received.
+//
+// It returns a byte slice containing the zip file, or an error.
+func (c *Client) DownloadVoiceSamples(voiceId string) ([]byte, error) {
+	samples, err := c.GetVoiceSamples(voiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	b := bytes.Buffer{}
+	zw := zip.NewWriter(&b)
+	for i, sample := range samples {
+		name := path.Base(strings.ReplaceAll(sample.FileName, "\\", "/"))
+		if name == "." || name == "/" {
+			name = sample.SampleId
+		}
+		fw, err := zw.Create(fmt.Sprintf("%02d_%s", i+1, name))
+		if err != nil {
+			return nil, err
+		}
+		err = c.doRequest(c.ctx, fw, http.MethodGet, fmt.Sprintf("%s/voices/%s/samples/%s/audio", c.baseURL, voiceId, sample.SampleId), &bytes.Buffer{}, contentTypeJSON)
+		if err != nil {
+			return nil, fmt.Errorf("sample %s: %w", sample.SampleId, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
 // NextHistoryPageFunc represent functions that can be used to access subsequent history pages. It is
 // returned by the GetHistory client method.
 //
@@ -671,13 +2605,21 @@ func (c *Client) GetHistory(queries ...QueryFunc) (GetHistoryResponse, NextHisto
 	}
 
 	nextPageFunc := func(qf ...QueryFunc) (GetHistoryResponse, NextHistoryPageFunc, error) {
-		// TODO copy to new slice to avoid unexpected issues if query changes after few calls.
-		qf = append(queries, append(qf, StartAfter(historyResp.LastHistoryItemId))...)
-		return c.GetHistory(qf...)
+		// The queries are copied so that the next pages don't share, and overwrite, each other's cursors.
+		next := append(queries[:len(queries):len(queries)], qf...)
+		next = append(next, StartAfter(historyResp.LastHistoryItemId))
+		return c.GetHistory(next...)
 	}
 	return historyResp, nextPageFunc, nil
 }
 
+// BuildGetHistoryRequest prepares, without sending, the request that GetHistory would send.
+//
+// It takes the same optional list of QueryFunc 'queries' as GetHistory and returns the prepared request or an error.
+func (c *Client) BuildGetHistoryRequest(queries ...QueryFunc) (*http.Request, error) {
+	return c.buildRequest(http.MethodGet, fmt.Sprintf("%s/history", c.baseURL), nil, contentTypeJSON, queries...)
+}
+
 // GetHistoryItem retrieves a specific history item by its ID.
 //
 // It takes a string argument 'representing the ID of the history item to be retrieved.
@@ -698,6 +2640,28 @@ func (c *Client) GetHistoryItem(itemId string) (HistoryItem, error) {
 	return historyItem, nil
 }
 
+// GetHistoryItems retrieves multiple history items by their IDs.
+//
+// It takes a slice of strings representing the IDs of the history items to be retrieved. Like
+// DeleteHistoryItems, it sends one request per item, a few of them concurrently, and stops once the client's
+// context is done.
+//
+// It returns a slice of HistoryItem objects in the same order as the IDs and an error combining the errors of
+// all the items that failed to be retrieved, if any. The items that failed to be retrieved are left as zero
+// values in the slice, so that partial results can still be used.
+func (c *Client) GetHistoryItems(itemIds []string) ([]HistoryItem, error) {
+	items := make([]HistoryItem, len(itemIds))
+	err := c.forEachConcurrently(len(itemIds), historyWorkers, false, func(i int) error {
+		item, err := c.GetHistoryItem(itemIds[i])
+		if err != nil {
+			return fmt.Errorf("history item %s: %w", itemIds[i], err)
+		}
+		items[i] = item
+		return nil
+	})
+	return items, err
+}
+
 // DeleteHistoryItem deletes a specific history item by its ID.
 //
 // It takes a string argument representing the ID of the history item to be deleted.
@@ -707,6 +2671,26 @@ func (c *Client) DeleteHistoryItem(itemId string) error {
 	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodDelete, fmt.Sprintf("%s/history/%s", c.baseURL, itemId), &bytes.Buffer{}, contentTypeJSON)
 }
 
+// historyWorkers is the number of requests DeleteHistoryItems and GetHistoryItems send concurrently.
+const historyWorkers = 4
+
+// DeleteHistoryItems deletes multiple history items by their IDs.
+//
+// It takes a slice of strings representing the IDs of the history items to be deleted. As the API has no bulk
+// deletion endpoint, the items are deleted with one request each, a few of them concurrently. Deletion carries
+// on when an item fails to be deleted, but stops once the client's context is done.
+//
+// It returns nil if all items were deleted or an error otherwise. The error combines the errors of all the
+// items that failed to be deleted, each of which can be matched with errors.Is and errors.As.
+func (c *Client) DeleteHistoryItems(itemIds []string) error {
+	return c.forEachConcurrently(len(itemIds), historyWorkers, false, func(i int) error {
+		if err := c.DeleteHistoryItem(itemIds[i]); err != nil {
+			return fmt.Errorf("history item %s: %w", itemIds[i], err)
+		}
+		return nil
+	})
+}
+
 // GetHistoryItemAudio retrieves the audio data for a specific history item by its ID.
 //
 // It takes a string argument representing the ID of the history item for which the audio
@@ -722,6 +2706,30 @@ func (c *Client) GetHistoryItemAudio(itemId string) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// StreamHistoryItemAudio retrieves the audio data for a specific history item by its ID and copies it
+// to the given writer as it is received.
+//
+// It takes an io.Writer argument to which the audio data will be copied and a string argument
+// representing the ID of the history item. Unlike GetHistoryItemAudio, the audio is never buffered
+// in full, which makes this method better suited for large items.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) StreamHistoryItemAudio(w io.Writer, itemId string) error {
+	return c.doStreamRequest(c.ctx, w, http.MethodGet, fmt.Sprintf("%s/history/%s/audio", c.baseURL, itemId), &bytes.Buffer{}, contentTypeJSON)
+}
+
+// HistoryItemAudioReader retrieves the audio data for a specific history item by its ID as it is received.
+//
+// It takes a string argument representing the ID of the history item. Like StreamHistoryItemAudio, the audio
+// is never buffered in full, which makes this method suited for re-serving large items, e.g. from an
+// http.Handler.
+//
+// It returns an io.ReadCloser with the audio data, which must be closed to release the connection, or an
+// error if the request fails.
+func (c *Client) HistoryItemAudioReader(itemId string) (io.ReadCloser, error) {
+	return c.doReaderRequest(c.ctx, http.MethodGet, fmt.Sprintf("%s/history/%s/audio", c.baseURL, itemId), &bytes.Buffer{}, contentTypeJSON)
+}
+
 // DownloadHistoryAudio downloads the audio data for a one or more history items.
 //
 // It takes a DownloadHistoryRequest argument that specifies the history item(s) to download.
@@ -743,6 +2751,35 @@ func (c *Client) DownloadHistoryAudio(dlReq DownloadHistoryRequest) ([]byte, err
 	return b.Bytes(), nil
 }
 
+// BuildDownloadHistoryAudioRequest prepares, without sending, the request that DownloadHistoryAudio would send.
+//
+// It takes the same DownloadHistoryRequest argument as DownloadHistoryAudio and returns the prepared request
+// or an error.
+func (c *Client) BuildDownloadHistoryAudioRequest(dlReq DownloadHistoryRequest) (*http.Request, error) {
+	reqBody, err := json.Marshal(dlReq)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildRequest(http.MethodPost, fmt.Sprintf("%s/history/download", c.baseURL), reqBody, contentTypeJSON)
+}
+
+// StreamDownloadHistoryAudio downloads the audio data for one or more history items and copies it
+// to the given writer as it is received.
+//
+// It takes an io.Writer argument to which the downloaded data will be copied and a DownloadHistoryRequest
+// argument that specifies the history item(s) to download. As with DownloadHistoryAudio, a single item ID
+// results in a mpeg encoded audio file while multiple item IDs result in a zip file.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) StreamDownloadHistoryAudio(w io.Writer, dlReq DownloadHistoryRequest) error {
+	reqBody, err := json.Marshal(dlReq)
+	if err != nil {
+		return err
+	}
+
+	return c.doStreamRequest(c.ctx, w, http.MethodPost, fmt.Sprintf("%s/history/download", c.baseURL), bytes.NewBuffer(reqBody), contentTypeJSON)
+}
+
 // GetSubscription retrieves the subscription details for the user.
 //
 // It returns a Subscription object representing the subscription details, or an error.
@@ -781,3 +2818,182 @@ func (c *Client) GetUser() (User, error) {
 
 	return user, nil
 }
+
+// GetCharacterUsage retrieves the number of characters used by the user over a period of time, in time buckets.
+//
+// It takes the start and end of the period and an optional list of QueryFunc 'queries' to modify the request.
+// The QueryFunc functions relevant for this method are UsageBreakdown and UsageAggregationInterval.
+//
+// It returns a UsageStats object or an error.
+func (c *Client) GetCharacterUsage(start, end time.Time, queries ...QueryFunc) (UsageStats, error) {
+	period := func(q *url.Values) {
+		q.Set("start_unix", strconv.FormatInt(start.UnixMilli(), 10))
+		q.Set("end_unix", strconv.FormatInt(end.UnixMilli(), 10))
+	}
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/usage/character-stats", c.baseURL), &bytes.Buffer{}, contentTypeJSON, append([]QueryFunc{period}, queries...)...)
+	if err != nil {
+		return UsageStats{}, err
+	}
+
+	var stats UsageStats
+	if err := json.Unmarshal(b.Bytes(), &stats); err != nil {
+		return UsageStats{}, err
+	}
+	return stats, nil
+}
+
+// GetProjects retrieves the list of all projects of the user.
+//
+// It returns a slice of Project objects or an error.
+func (c *Client) GetProjects() ([]Project, error) {
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/projects", c.baseURL), &bytes.Buffer{}, contentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var projectsResp GetProjectsResponse
+	if err := json.Unmarshal(b.Bytes(), &projectsResp); err != nil {
+		return nil, err
+	}
+
+	return projectsResp.Projects, nil
+}
+
+// CreateProject creates a new project from a document or a web page.
+//
+// It takes a CreateProjectRequest argument that contains the information of the project to be created.
+//
+// It returns the newly created Project, or an error.
+func (c *Client) CreateProject(projectReq CreateProjectRequest) (Project, error) {
+	reqBodyBuf, contentType, err := projectReq.buildRequestBody()
+	if err != nil {
+		return Project{}, err
+	}
+	b := bytes.Buffer{}
+	err = c.doRequest(c.ctx, &b, http.MethodPost, fmt.Sprintf("%s/projects/add", c.baseURL), reqBodyBuf, contentType)
+	if err != nil {
+		return Project{}, err
+	}
+	var projectResp AddProjectResponse
+	if err := json.Unmarshal(b.Bytes(), &projectResp); err != nil {
+		return Project{}, err
+	}
+	return projectResp.Project, nil
+}
+
+// GetProject retrieves a project, including its chapters, by its ID.
+//
+// It takes a string argument that represents the ID of the project to be retrieved.
+//
+// It returns the Project or an error.
+func (c *Client) GetProject(projectId string) (Project, error) {
+	project := Project{}
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/projects/%s", c.baseURL, projectId), &bytes.Buffer{}, contentTypeJSON)
+	if err != nil {
+		return project, err
+	}
+
+	if err := json.Unmarshal(b.Bytes(), &project); err != nil {
+		return project, err
+	}
+
+	return project, nil
+}
+
+// DeleteProject deletes a project by its ID.
+//
+// It takes a string argument that represents the ID of the project to be deleted.
+//
+// It returns nil if successful or an error otherwise.
+func (c *Client) DeleteProject(projectId string) error {
+	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodDelete, fmt.Sprintf("%s/projects/%s", c.baseURL, projectId), &bytes.Buffer{}, contentTypeJSON)
+}
+
+// ConvertProject starts the conversion of a project and all of its chapters.
+//
+// It takes a string argument that represents the ID of the project to be converted. The conversion runs
+// asynchronously, its progress can be followed with GetProject.
+//
+// It returns nil if the conversion was started successfully or an error otherwise.
+func (c *Client) ConvertProject(projectId string) error {
+	return c.doRequest(c.ctx, &bytes.Buffer{}, http.MethodPost, fmt.Sprintf("%s/projects/%s/convert", c.baseURL, projectId), &bytes.Buffer{}, contentTypeJSON)
+}
+
+// GetProjectChapters retrieves the list of all chapters of a project.
+//
+// It takes a string argument that represents the ID of the project.
+//
+// It returns a slice of Chapter objects or an error.
+func (c *Client) GetProjectChapters(projectId string) ([]Chapter, error) {
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/projects/%s/chapters", c.baseURL, projectId), &bytes.Buffer{}, contentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var chaptersResp GetChaptersResponse
+	if err := json.Unmarshal(b.Bytes(), &chaptersResp); err != nil {
+		return nil, err
+	}
+
+	return chaptersResp.Chapters, nil
+}
+
+// GetChapterSnapshots retrieves the list of snapshots of a chapter, one for each time the chapter was converted.
+//
+// It takes a string argument that represents the ID of the project and a string argument that represents the ID
+// of the chapter.
+//
+// It returns a slice of ChapterSnapshot objects or an error.
+func (c *Client) GetChapterSnapshots(projectId, chapterId string) ([]ChapterSnapshot, error) {
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/projects/%s/chapters/%s/snapshots", c.baseURL, projectId, chapterId), &bytes.Buffer{}, contentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshotsResp GetChapterSnapshotsResponse
+	if err := json.Unmarshal(b.Bytes(), &snapshotsResp); err != nil {
+		return nil, err
+	}
+
+	return snapshotsResp.Snapshots, nil
+}
+
+// GetProjectSnapshots retrieves the list of snapshots of a project, one for each time the project was converted.
+//
+// It takes a string argument that represents the ID of the project.
+//
+// It returns a slice of ProjectSnapshot objects or an error.
+func (c *Client) GetProjectSnapshots(projectId string) ([]ProjectSnapshot, error) {
+	b := bytes.Buffer{}
+	err := c.doRequest(c.ctx, &b, http.MethodGet, fmt.Sprintf("%s/projects/%s/snapshots", c.baseURL, projectId), &bytes.Buffer{}, contentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshotsResp GetProjectSnapshotsResponse
+	if err := json.Unmarshal(b.Bytes(), &snapshotsResp); err != nil {
+		return nil, err
+	}
+
+	return snapshotsResp.Snapshots, nil
+}
+
+// DownloadProjectSnapshot downloads the audio of a project snapshot and copies it to the given writer as it
+// is received.
+//
+// It takes a string argument that represents the ID of the project, a string argument that represents the ID
+// of the snapshot and an io.Writer argument to which the audio will be copied. As with TextToSpeechStream, the
+// timeout of the client only applies until the first bytes of audio are received, so downloading hours of audio
+// isn't cut short.
+//
+// It returns nil if successful or an error otherwise. If the conversion of the project is not complete, the
+// error is a *NotReadyError and the download can be retried later.
+func (c *Client) DownloadProjectSnapshot(projectId, snapshotId string, w io.Writer) error {
+	err := c.doStreamRequest(c.ctx, w, http.MethodPost, fmt.Sprintf("%s/projects/%s/snapshots/%s/stream", c.baseURL, projectId, snapshotId), &bytes.Buffer{}, contentTypeJSON)
+	return notReady(err)
+}
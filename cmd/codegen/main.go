@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
 	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -25,9 +30,11 @@ const (
 // Run 'go generate' after adding new methods with a '{{.ReceiverType}}' pointer receiver.
 
 package elevenlabs
-
-import "io"
-{{range .Functions}}
+{{if .Imports}}
+import (
+{{range .Imports}}	{{.}}
+{{end}})
+{{end}}{{range .Functions}}
 // {{.FuncIdent}} calls the {{.FuncIdent}} method on the default client.
 func {{.FuncIdent}}{{.FuncParams}}{{.FuncResults}} {
 	{{if .FuncResults}}return {{end}}{{.MethodReceiver}}.{{.FuncIdent}}{{.FuncArgs}}
@@ -35,11 +42,19 @@ func {{.FuncIdent}}{{.FuncParams}}{{.FuncResults}} {
 {{end}}`
 )
 
-var sourceFiles []string = []string{"client.go", "models.go", "errors.go"}
+// sourceFiles are the files whose methods get a default-client function, in the order they are generated. Files
+// with build constraints, such as exec.go, aren't listed: their functions are declared next to their methods,
+// under the same constraints.
+var sourceFiles []string = []string{"client.go", "models.go", "errors.go", "dialog.go", "dump.go"}
+
+// skipMethods are the methods that get no default-client function. Closing the default client would break
+// every other user of it in the process.
+var skipMethods = map[string]bool{"Close": true}
 
 type proxyFuncFile struct {
 	GeneratorPath string
 	ReceiverType  string
+	Imports       []string
 	Functions     []proxyFunc
 }
 
@@ -53,7 +68,13 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	n, err := generate(&b, pkg.Files)
+	files := map[string]*ast.File{}
+	for _, name := range sourceFiles {
+		if f, ok := pkg.Files[name]; ok {
+			files[name] = f
+		}
+	}
+	n, err := generate(&b, files)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -73,9 +94,14 @@ func generate(w io.Writer, pkgFiles map[string]*ast.File) (int, error) {
 		GeneratorPath: g,
 		ReceiverType:  receiverType,
 	}
-	for _, pf := range pkgFiles {
+	imports := map[string]bool{}
+	for _, name := range sortedFileNames(pkgFiles) {
+		pf := pkgFiles[name]
 		methods := ptrRcvMethods(pf, receiverType)
 		for _, m := range methods {
+			for _, imp := range usedImports(pf, m.Type) {
+				imports[imp] = true
+			}
 			sFile.Functions = append(sFile.Functions, proxyFunc{
 				FuncIdent:      m.Name.Name,
 				FuncParams:     genTypedParams(m.Type.Params),
@@ -86,14 +112,81 @@ func generate(w io.Writer, pkgFiles map[string]*ast.File) (int, error) {
 		}
 		total += len(methods)
 	}
+	for imp := range imports {
+		sFile.Imports = append(sFile.Imports, imp)
+	}
+	sort.Strings(sFile.Imports)
 	t := template.Must(template.New("").Parse(genFileTemplate))
-	if err := t.Execute(w, sFile); err != nil {
+	b := bytes.Buffer{}
+	if err := t.Execute(&b, sFile); err != nil {
+		return 0, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	if _, err := w.Write(src); err != nil {
 		return 0, err
 	}
 
 	return total, nil
 }
 
+// sortedFileNames returns the names of the files in the order of sourceFiles, followed by the files that aren't
+// listed in alphabetical order, so that the output doesn't depend on map iteration order.
+func sortedFileNames(files map[string]*ast.File) []string {
+	rank := func(name string) int {
+		for i, f := range sourceFiles {
+			if f == name {
+				return i
+			}
+		}
+		return len(sourceFiles)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// usedImports returns the import specs of the packages of f that are referred to by node, e.g. "io" for
+// io.Writer, including their name if they were imported under another one.
+func usedImports(f *ast.File, node ast.Node) []string {
+	paths := map[string]string{}
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name, spec := path.Base(p), strconv.Quote(p)
+		if imp.Name != nil && imp.Name.Name != name {
+			name = imp.Name.Name
+			spec = name + " " + spec
+		}
+		paths[name] = spec
+	}
+	var used []string
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if p, ok := paths[id.Name]; ok {
+					used = append(used, p)
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return used
+}
+
 func parsePackage(pkgName string, path string, excludeFiles []string) (*ast.Package, error) {
 	excludeFunc := func(fi fs.FileInfo) bool {
 		for _, f := range excludeFiles {
@@ -121,7 +214,7 @@ func ptrRcvMethods(f *ast.File, receiverTypeStr string) []*ast.FuncDecl {
 			funcDecl.Name.IsExported() &&
 			funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 { // If declaration is a exported function that has a receiver (i.e. method)
 			if ptrRecvExpr, ok := funcDecl.Recv.List[0].Type.(*ast.StarExpr); ok &&
-				fmt.Sprint(ptrRecvExpr.X) == receiverTypeStr {
+				fmt.Sprint(ptrRecvExpr.X) == receiverTypeStr && !skipMethods[funcDecl.Name.Name] {
 				methodDecls = append(methodDecls, funcDecl)
 			}
 		}
@@ -184,19 +277,7 @@ func genFuncReturnTypes(fl *ast.FieldList) string {
 }
 
 func exprToString(expr ast.Expr) string {
-	switch fieldType := expr.(type) {
-	case *ast.ArrayType:
-		return fmt.Sprintf("[]%s", exprToString(fieldType.Elt))
-	case *ast.Ellipsis:
-		return fmt.Sprintf("...%s", exprToString(fieldType.Elt))
-	case *ast.StarExpr:
-		return fmt.Sprintf("*%s", exprToString(fieldType.X))
-	case *ast.FuncType:
-		return fmt.Sprintf("func%s%s", genTypedParams(fieldType.Params), genFuncReturnTypes(fieldType.Results))
-	case *ast.SelectorExpr:
-		return fmt.Sprintf("%s.%s", fieldType.X, fieldType.Sel)
-	}
-	return fmt.Sprintf("%s", expr)
+	return types.ExprString(expr)
 }
 
 func getCurrentRelPath() (string, error) {
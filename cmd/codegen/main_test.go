@@ -165,3 +165,47 @@ func TestGenFuncFromMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateImports(t *testing.T) {
+	testSrc := `
+import (
+	"io"
+	nethttp "net/http"
+	"time"
+)
+
+type Client struct{}
+
+func (c *Client) Stream(w io.Writer, text chan string) (nethttp.Header, error) {
+	return nil, nil
+}
+
+func (c *Client) Close() {}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "testSrc", packageDef+testSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := bytes.Buffer{}
+	if _, err := generate(&b, map[string]*ast.File{"testSrc": f}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := parser.ParseFile(token.NewFileSet(), "", b.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("Expected the generated code to parse, got error: %q\n%s", err, b.String())
+	}
+	var gotImports []string
+	for _, imp := range out.Imports {
+		spec := imp.Path.Value
+		if imp.Name != nil {
+			spec = imp.Name.Name + " " + spec
+		}
+		gotImports = append(gotImports, spec)
+	}
+	if exp := []string{`"io"`, `nethttp "net/http"`}; strings.Join(gotImports, " ") != strings.Join(exp, " ") {
+		t.Errorf("Expected imports %v, got %v", exp, gotImports)
+	}
+	if s := b.String(); !strings.Contains(s, "text chan string") || strings.Contains(s, "func Close") {
+		t.Errorf("Unexpected generated code:\n%s", s)
+	}
+}
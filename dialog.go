@@ -0,0 +1,177 @@
+package elevenlabs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DialogTurn is a turn of a Dialog: a text spoken by a voice.
+type DialogTurn struct {
+	VoiceID string
+	Text    string
+}
+
+// Dialog converts text spoken in turns by several voices to speech, such as the lines of the characters of
+// a narration, into a single audio stream.
+//
+// The voice of a stream-input session can't be changed once it is open, so every turn is converted in its
+// own session, as with TextToSpeechInputStreamReader. To keep the pauses between turns short, the session of
+// the next turn is started while the current turn is being written and its audio is held back until the
+// current turn is done. A Dialog is not safe for concurrent use.
+type Dialog struct {
+	client  *Client
+	modelID string
+	ttsReq  TextToSpeechInputStreamingRequest
+	queries []QueryFunc
+	turns   []DialogTurn
+}
+
+// NewDialog returns a new Dialog, with no turns, that uses the client to convert its turns to speech.
+//
+// It takes the ID of the model to be used, a TextToSpeechInputStreamingRequest argument that contains the
+// settings for the conversion and an optional list of QueryFunc 'queries' to modify the requests, which are
+// the same for every turn.
+func (c *Client) NewDialog(modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) *Dialog {
+	return &Dialog{
+		client:  c,
+		modelID: modelID,
+		ttsReq:  ttsReq,
+		queries: queries,
+	}
+}
+
+// Add enqueues a turn in which text is spoken by the voice with the given ID.
+//
+// It returns the Dialog, so that calls can be chained.
+func (d *Dialog) Add(voiceID, text string) *Dialog {
+	d.turns = append(d.turns, DialogTurn{VoiceID: voiceID, Text: text})
+	return d
+}
+
+// Turns returns the turns enqueued since the last call to Stream.
+func (d *Dialog) Turns() []DialogTurn {
+	return append([]DialogTurn(nil), d.turns...)
+}
+
+// Stream converts the enqueued turns to speech and writes the audio to w, one turn after the other in the
+// order they were added. The queue is emptied, so that the Dialog can be used for the following turns.
+//
+// It returns nil once all the turns were written, or an error. Once a turn fails, the following turns are
+// not written and the sessions that were already started are stopped.
+func (d *Dialog) Stream(w io.Writer) error {
+	turns := d.turns
+	d.turns = nil
+
+	outs := make([]*dialogOutput, len(turns))
+	for i := range outs {
+		outs[i] = &dialogOutput{done: make(chan struct{})}
+	}
+
+	// The turns are handed to w one at a time, each one once the previous one is done.
+	var writeErr error
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i, out := range outs {
+			err := out.start(w)
+			if err == nil {
+				<-out.done
+				if out.ok {
+					continue
+				}
+			}
+			writeErr = err
+			for _, next := range outs[i+1:] {
+				next.fail(errDialogStopped)
+			}
+			return
+		}
+	}()
+
+	// Up to two sessions are open at the same time: the one of the turn being written and the next one.
+	err := d.client.forEachConcurrently(len(turns), 2, true, func(i int) error {
+		err := d.client.TextToSpeechInputStreamReader(strings.NewReader(turns[i].Text), outs[i], turns[i].VoiceID, d.modelID, d.ttsReq, d.queries...)
+		outs[i].finish(err == nil)
+		if errors.Is(err, errDialogStopped) {
+			// Stopped because an earlier turn failed, whose error is reported instead.
+			return nil
+		}
+		return err
+	})
+	// Turns that weren't started are reported as failed, so that the writing goroutine returns.
+	for _, out := range outs {
+		out.finish(false)
+	}
+	<-written
+
+	if writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// errDialogStopped is returned to the sessions of the turns following a failed turn of a Dialog.
+var errDialogStopped = errors.New("dialog stopped")
+
+// dialogOutput is the io.Writer to which the audio of a turn of a Dialog is written. The audio is buffered
+// until the turn is started, and then written through to the dialog's io.Writer.
+type dialogOutput struct {
+	mu   sync.Mutex
+	w    io.Writer
+	buf  bytes.Buffer
+	err  error
+	once sync.Once
+	ok   bool
+	done chan struct{}
+}
+
+func (o *dialogOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return 0, o.err
+	}
+	if o.w == nil {
+		return o.buf.Write(p)
+	}
+	n, err := o.w.Write(p)
+	if err != nil {
+		o.err = err
+	}
+	return n, err
+}
+
+// start writes the audio buffered so far to w and has the following audio written directly to it.
+func (o *dialogOutput) start(w io.Writer) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf.Len() > 0 {
+		if _, err := w.Write(o.buf.Bytes()); err != nil {
+			o.err = err
+			return err
+		}
+		o.buf.Reset()
+	}
+	o.w = w
+	return nil
+}
+
+// fail has the following writes return err.
+func (o *dialogOutput) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// finish marks the turn as done, successfully if ok is true. Only the first call has an effect.
+func (o *dialogOutput) finish(ok bool) {
+	o.once.Do(func() {
+		o.ok = ok
+		close(o.done)
+	})
+}
@@ -0,0 +1,126 @@
+package elevenlabs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+)
+
+// dumpRecorder keeps the dump of the last HTTP exchange of a client configured with WithRequestDumps and writes
+// the dumps of all exchanges to w, if set. It is safe for concurrent use and is shared by all copies of a Client
+// made with With. A nil *dumpRecorder records nothing.
+type dumpRecorder struct {
+	mu   sync.Mutex
+	w    io.Writer
+	last []byte
+}
+
+// start returns the dump of an exchange, starting with the dump of its request as returned by
+// httputil.DumpRequestOut, in which apiKey is redacted. It returns nil if r is nil.
+func (r *dumpRecorder) start(reqDump []byte, apiKey string) *exchangeDump {
+	if r == nil {
+		return nil
+	}
+	d := &exchangeDump{recorder: r}
+	d.buf.WriteString(">>> HTTP REQUEST >>>\n")
+	head, body := splitDump(reqDump)
+	if apiKey != "" {
+		head = strings.ReplaceAll(head, apiKey, "[REDACTED]")
+		body = bytes.ReplaceAll(body, []byte(apiKey), []byte("[REDACTED]"))
+	}
+	d.buf.WriteString(head)
+	d.body(body)
+	return d
+}
+
+func (r *dumpRecorder) record(dump []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last = dump
+	if r.w != nil {
+		r.w.Write(dump)
+	}
+}
+
+// exchangeDump is the dump of an HTTP exchange in progress. A nil *exchangeDump ignores all calls.
+type exchangeDump struct {
+	recorder *dumpRecorder
+	buf      bytes.Buffer
+}
+
+// response adds the status line and headers of resp to the dump.
+func (d *exchangeDump) response(resp *http.Response) {
+	if d == nil {
+		return
+	}
+	respDump, _ := httputil.DumpResponse(resp, false)
+	d.buf.WriteString("<<< HTTP RESPONSE <<<\n")
+	d.buf.Write(respDump)
+}
+
+// body adds a request or response body to the dump, indented if it is JSON.
+func (d *exchangeDump) body(b []byte) {
+	if d == nil || len(b) == 0 {
+		return
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, b, "", "  ") == nil {
+		b = indented.Bytes()
+	}
+	d.buf.Write(b)
+	d.buf.WriteString("\n")
+}
+
+// notef adds a line to the dump, e.g. in place of an audio body or for an error.
+func (d *exchangeDump) notef(format string, v ...interface{}) {
+	if d == nil {
+		return
+	}
+	fmt.Fprintf(&d.buf, "["+format+"]\n", v...)
+}
+
+// done records the dump. It must be called once the exchange is over.
+func (d *exchangeDump) done() {
+	if d == nil {
+		return
+	}
+	d.buf.WriteString("\n")
+	d.recorder.record(d.buf.Bytes())
+}
+
+// splitDump splits the dump of a request into its head, i.e. the request line and headers, and its body.
+func splitDump(dump []byte) (string, []byte) {
+	if i := bytes.Index(dump, []byte("\r\n\r\n")); i >= 0 {
+		return string(dump[:i+4]), dump[i+4:]
+	}
+	return string(dump), nil
+}
+
+// WithRequestDumps returns an Option that captures the dumps of the client's HTTP requests and their responses,
+// e.g. to attach the last failing exchange to a bug report without enabling the client's logging. JSON bodies
+// are indented, audio bodies are left out and the API key is redacted.
+//
+// The dump of every exchange is written to w, if it isn't nil, and the dump of the last one can be retrieved
+// with LastRequestDump. Writes to w are serialized, and their errors are ignored. The captured dumps are shared
+// with the clients created with With. WebSocket sessions of TextToSpeechInputStream are not captured.
+func WithRequestDumps(w io.Writer) Option {
+	return func(c *Client) {
+		c.dumps = &dumpRecorder{w: w}
+	}
+}
+
+// LastRequestDump returns the dump of the last HTTP exchange of a client configured with WithRequestDumps, or an
+// empty string if there was none.
+func (c *Client) LastRequestDump() string {
+	if c.dumps == nil {
+		return ""
+	}
+	c.dumps.mu.Lock()
+	defer c.dumps.mu.Unlock()
+	return string(c.dumps.last)
+}
@@ -1,18 +1,30 @@
 package elevenlabs_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/haguro/elevenlabs-go"
+	"github.com/clearlyip/elevenlabs-go"
+	"github.com/gorilla/websocket"
 )
 
 const (
@@ -75,17 +87,110 @@ func testServer(t *testing.T, config testServerConfig) *httptest.Server {
 	}))
 }
 
+// testWSServer starts a WebSocket server that calls handler with every accepted connection and its
+// zero-based index. Connections are closed when handler returns.
+func testWSServer(t *testing.T, handler func(conn *websocket.Conn, n int)) *httptest.Server {
+	t.Helper()
+	var upgrader websocket.Upgrader
+	var mu sync.Mutex
+	var count int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		mu.Lock()
+		n := count
+		count++
+		mu.Unlock()
+		handler(conn, n)
+	}))
+}
+
+// wsURL returns the WebSocket URL of a server started with testWSServer.
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// serveInputStream reads the messages of a stream-input session until the empty text message is received,
+// then replies with the given audio and a final message. It returns the text of all received messages.
+func serveInputStream(t *testing.T, conn *websocket.Conn, audio string) []string {
+	t.Helper()
+	var texts []string
+	for {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Errorf("Server: failed to read message: %s", err)
+			return texts
+		}
+		text, _ := msg["text"].(string)
+		texts = append(texts, text)
+		if text == "" {
+			break
+		}
+	}
+	conn.WriteJSON(map[string]any{"audio": base64.StdEncoding.EncodeToString([]byte(audio))})
+	conn.WriteJSON(map[string]any{"isFinal": true})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return texts
+}
+
+// sendText returns a channel that receives the given chunks and is then closed.
+func sendText(chunks ...string) chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, c := range chunks {
+			ch <- c
+		}
+	}()
+	return ch
+}
+
 func TestDefaultClientSetup(t *testing.T) {
+	// The default client is created from the environment, which must not leak a real API key or base URL.
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	t.Setenv("ELEVENLABS_BASE_URL", "")
 	baseURL := "http://localhost:1234/"
 	defaultClient := elevenlabs.MockDefaultClient(baseURL)
 	elevenlabs.SetAPIKey(mockAPIKey)
 	elevenlabs.SetTimeout(mockTimeout)
 	expected := elevenlabs.NewMockClient(context.Background(), baseURL, mockAPIKey, mockTimeout)
-	if !reflect.DeepEqual(expected, defaultClient) {
+	if !elevenlabs.EqualClients(expected, defaultClient) {
 		t.Errorf("Default client set up is incorrect %+v", defaultClient)
 	}
 }
 
+func TestDefaultClientConcurrentSetters(t *testing.T) {
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	t.Setenv("ELEVENLABS_BASE_URL", "")
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusOK,
+		responseBody:   []byte("[]"),
+	})
+	defer server.Close()
+	elevenlabs.MockDefaultClient(server.URL)
+	elevenlabs.SetAPIKey(mockAPIKey)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			elevenlabs.SetAPIKey(mockAPIKey)
+			elevenlabs.SetTimeout(mockTimeout)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := elevenlabs.GetModels(); err != nil {
+			t.Errorf("Expected no errors, got error: %q", err)
+		}
+	}
+	<-done
+}
+
 func TestRequestTimeout(t *testing.T) {
 	t.Parallel()
 	server := testServer(t, testServerConfig{
@@ -105,6 +210,424 @@ func TestRequestTimeout(t *testing.T) {
 		t.Errorf("Expected context deadline exceeded error returned, got err")
 	}
 }
+
+func TestWithTimeout(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusOK,
+		responseBody:   []byte("[]"),
+		responseDelay:  200 * time.Millisecond,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	_, err := client.With(elevenlabs.WithTimeout(50 * time.Millisecond)).GetModels()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded error with the overridden timeout, got %v", err)
+	}
+	if _, err := client.GetModels(); err != nil {
+		t.Errorf("Expected the original client timeout to be unchanged, got error: %q", err)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	client.Close()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("Expected the idle connection to be closed")
+	}
+	if _, err := client.GetModels(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error after Close, got %v", err)
+	}
+}
+
+func TestClientCloseCopies(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusOK,
+		responseBody:   testRespBodies["TestGetModels"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	// Closing a copy leaves the original and the other copies usable.
+	copy1, copy2 := client.With(), client.With()
+	copy1.Close()
+	for name, c := range map[string]*elevenlabs.Client{"original": client, "other copy": copy2} {
+		if _, err := c.GetModels(); err != nil {
+			t.Errorf("Expected no errors from the %s after closing a copy, got error: %q", name, err)
+		}
+	}
+
+	// A copy with its own context is closed on its own.
+	own := client.With(elevenlabs.WithContext(context.Background()))
+	own.Close()
+	if _, err := own.GetModels(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error after closing a copy with its own context, got %v", err)
+	}
+	if _, err := client.GetModels(); err != nil {
+		t.Errorf("Expected no errors from the original, got error: %q", err)
+	}
+
+	// Closing the original closes the copies sharing its context.
+	client.Close()
+	if _, err := copy2.GetModels(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error from a copy after closing the original, got %v", err)
+	}
+}
+
+func TestClientCloseCustomHTTPClient(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithHTTPClient(&http.Client{Transport: transport}))
+
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	client.Close()
+	select {
+	case <-closed:
+		t.Error("Expected the idle connections of the caller's transport to be left open")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        testRespBodies["TestGetModels"],
+	})
+	defer server.Close()
+	var logs bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	client := elevenlabs.NewClientWithOptions(mockAPIKey,
+		elevenlabs.WithContext(ctx),
+		elevenlabs.WithBaseURL(server.URL),
+		elevenlabs.WithTimeout(mockTimeout),
+		elevenlabs.WithLogger(log.New(&logs, "", 0)),
+	)
+
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if !strings.Contains(logs.String(), server.URL) {
+		t.Errorf("Expected the request to be logged to the given logger, got %q", logs.String())
+	}
+	cancel()
+	if _, err := client.GetModels(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error after canceling the parent context, got %v", err)
+	}
+}
+
+func TestPreCanceledContext(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var logs bytes.Buffer
+	client := elevenlabs.NewClientWithOptions(mockAPIKey,
+		elevenlabs.WithContext(ctx),
+		elevenlabs.WithBaseURL(server.URL),
+		elevenlabs.WithBaseWSURL(wsURL(server)),
+		elevenlabs.WithLogger(log.New(&logs, "", 0)),
+	)
+
+	calls := map[string]func() error{
+		"GetModels": func() error {
+			_, err := client.GetModels()
+			return err
+		},
+		"TextToSpeechStream": func() error {
+			return client.TextToSpeechStream(io.Discard, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+		},
+		"HistoryItemAudioReader": func() error {
+			_, err := client.HistoryItemAudioReader("itemID")
+			return err
+		},
+		"TextToSpeechInputStream": func() error {
+			return client.TextToSpeechInputStream(sendText("Hello "), nil, io.Discard, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err != context.Canceled {
+			t.Errorf("Expected %s to return context.Canceled, got %T: %v", name, err, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("Expected no requests to be sent, got %d", n)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected nothing to be logged, got %q", logs.String())
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	keys := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get("xi-api-key")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	t.Setenv("ELEVENLABS_API_KEY", "EnvAPIKey")
+	t.Setenv("ELEVENLABS_BASE_URL", server.URL)
+
+	if _, err := elevenlabs.NewClientFromEnv().GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := <-keys; got != "EnvAPIKey" {
+		t.Errorf("Expected the API key from the environment, got %q", got)
+	}
+	if _, err := elevenlabs.NewClientFromEnv(elevenlabs.WithAPIKey(mockAPIKey)).GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := <-keys; got != mockAPIKey {
+		t.Errorf("Expected WithAPIKey to take precedence over the environment, got %q", got)
+	}
+
+	t.Setenv("ELEVENLABS_API_KEY", "")
+	if _, err := elevenlabs.NewClientFromEnv().GetModels(); !errors.Is(err, elevenlabs.ErrMissingAPIKey) {
+		t.Errorf("Expected ErrMissingAPIKey without an API key in the environment, got %v", err)
+	}
+}
+
+func TestWithAPIKey(t *testing.T) {
+	const tenantKey = "TenantAPIKey"
+	var gotKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = append(gotKeys, r.Header.Get("xi-api-key"))
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	if _, err := client.With(elevenlabs.WithAPIKey(tenantKey)).GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expKeys := []string{tenantKey, mockAPIKey}
+	if !reflect.DeepEqual(expKeys, gotKeys) {
+		t.Errorf("Expected API keys %q to be sent, got %q", expKeys, gotKeys)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	headerCh := make(chan http.Header, 2)
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header
+		if !websocket.IsWebSocketUpgrade(r) {
+			w.Write([]byte("[]"))
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		serveInputStream(t, conn, "audio")
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	custom := client.With(
+		elevenlabs.WithHeader("X-Org-Id", "TestOrg"),
+		elevenlabs.WithHeaders(http.Header{"Xi-Api-Key": {"OtherKey"}, "Content-Type": {"text/plain"}, "X-Trace": {"a", "b"}}),
+	)
+
+	checkHeader := func(t *testing.T, header http.Header) {
+		t.Helper()
+		if got := header.Get("X-Org-Id"); got != "TestOrg" {
+			t.Errorf("Expected header X-Org-Id to be %q, got %q", "TestOrg", got)
+		}
+		if got := header.Values("X-Trace"); !reflect.DeepEqual([]string{"a", "b"}, got) {
+			t.Errorf("Expected header X-Trace to be %q, got %q", []string{"a", "b"}, got)
+		}
+		if got := header.Values("Xi-Api-Key"); !reflect.DeepEqual([]string{mockAPIKey}, got) {
+			t.Errorf("Expected header xi-api-key to be %q, got %q", mockAPIKey, got)
+		}
+		if got := header.Values("Content-Type"); !reflect.DeepEqual([]string{contentTypeJSON}, got) {
+			t.Errorf("Expected header Content-Type to be %q, got %q", contentTypeJSON, got)
+		}
+	}
+
+	if _, err := custom.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	checkHeader(t, <-headerCh)
+
+	wsClient := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithHeader("X-Org-Id", "TestOrg"), elevenlabs.WithHeaders(http.Header{"X-Trace": {"a", "b"}}))
+	responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+	if err := wsClient.TextToSpeechInputStream(sendText("Hello "), responses, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "}); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	checkHeader(t, <-headerCh)
+
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := (<-headerCh).Get("X-Org-Id"); got != "" {
+		t.Errorf("Expected the original client to send no X-Org-Id header, got %q", got)
+	}
+}
+
+func TestWithHeaderAccept(t *testing.T) {
+	acceptCh := make(chan string, 1)
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptCh <- r.Header.Get("Accept")
+		if !websocket.IsWebSocketUpgrade(r) {
+			w.Write([]byte("audio"))
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		serveInputStream(t, conn, "audio")
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	wsClient := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{Text: "Test text"}
+	streamReq := elevenlabs.TextToSpeechInputStreamingRequest{Text: " "}
+
+	testCases := []struct {
+		name string
+		opts []elevenlabs.Option
+		exp  string
+	}{
+		{name: "default", exp: "*/*"},
+		{name: "overridden", opts: []elevenlabs.Option{elevenlabs.WithHeader("Accept", "audio/mpeg")}, exp: "audio/mpeg"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := client.With(tc.opts...).TextToSpeech("TestVoiceID", ttsReq); err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if got := <-acceptCh; got != tc.exp {
+				t.Errorf("Expected TextToSpeech to send Accept %q, got %q", tc.exp, got)
+			}
+			if err := client.With(tc.opts...).TextToSpeechStream(&bytes.Buffer{}, "TestVoiceID", ttsReq); err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if got := <-acceptCh; got != tc.exp {
+				t.Errorf("Expected TextToSpeechStream to send Accept %q, got %q", tc.exp, got)
+			}
+			responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+			if err := wsClient.With(tc.opts...).TextToSpeechInputStream(sendText("Hello "), responses, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, streamReq); err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if got := <-acceptCh; got != tc.exp {
+				t.Errorf("Expected TextToSpeechInputStream to send Accept %q, got %q", tc.exp, got)
+			}
+			req, err := client.With(tc.opts...).BuildTextToSpeechRequest("TestVoiceID", ttsReq)
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if got := req.Header.Get("Accept"); got != tc.exp {
+				t.Errorf("Expected BuildTextToSpeechRequest to set Accept %q, got %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRequestHooks(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        []byte("[]"),
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	var reqEvent elevenlabs.RequestEvent
+	var respEvent elevenlabs.ResponseEvent
+	client.OnRequest = func(e elevenlabs.RequestEvent) { reqEvent = e }
+	client.OnResponse = func(e elevenlabs.ResponseEvent) { respEvent = e }
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expURL := server.URL + "/models"
+	if reqEvent.Method != http.MethodGet || reqEvent.URL != expURL {
+		t.Errorf("Expected request event for %s %s, got %+v", http.MethodGet, expURL, reqEvent)
+	}
+	if respEvent.Method != http.MethodGet || respEvent.URL != expURL || respEvent.StatusCode != http.StatusOK || respEvent.Err != nil {
+		t.Errorf("Unexpected response event %+v", respEvent)
+	}
+	if respEvent.Duration <= 0 {
+		t.Errorf("Expected response event duration to be positive, got %s", respEvent.Duration)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	type ctxKey struct{}
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        []byte("[]"),
+	})
+	defer server.Close()
+	var gotValue any
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		gotValue = r.Context().Value(ctxKey{})
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "span")
+	client := elevenlabs.NewMockClient(ctx, server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithHTTPClient(httpClient))
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if gotValue != "span" {
+		t.Errorf("Expected the custom transport to receive the parent context value %q, got %v", "span", gotValue)
+	}
+}
+
 func TestAPIErrorOnBadRequestAndUnauthorized(t *testing.T) {
 	for _, code := range [2]int{http.StatusBadRequest, http.StatusUnauthorized} {
 		t.Run(http.StatusText(code), func(t *testing.T) {
@@ -149,6 +672,29 @@ func TestValidationErrorOnUnprocessableEntity(t *testing.T) {
 	}
 }
 
+func TestValidationErrorFields(t *testing.T) {
+	var valErr elevenlabs.ValidationError
+	if err := json.Unmarshal(testRespBodies["TestValidationErrorFields"], &valErr); err != nil {
+		t.Fatalf("Failed to unmarshal ValidationError: %s", err)
+	}
+	if valErr.Detail == nil || len(*valErr.Detail) != 3 {
+		t.Fatalf("Expected 3 detail items, got %+v", valErr.Detail)
+	}
+	first := (*valErr.Detail)[0]
+	expLoc := []elevenlabs.ValidationErrorDetailLocItem{"body", "text"}
+	if !reflect.DeepEqual(expLoc, first.Loc) || first.Msg != "field required" || first.Type != "value_error.missing" {
+		t.Errorf("Unexpected first detail item %+v", first)
+	}
+	expFields := []string{"text", "voice_settings.stability", "optimize_streaming_latency"}
+	if !reflect.DeepEqual(expFields, valErr.Fields()) {
+		t.Errorf("Expected fields %q, got %q", expFields, valErr.Fields())
+	}
+	expErr := "validation error - text: field required; voice_settings.stability: ensure this value is less than or equal to 1.0; optimize_streaming_latency: value is not a valid integer"
+	if valErr.Error() != expErr {
+		t.Errorf("Expected error %q, got %q", expErr, valErr.Error())
+	}
+}
+
 func TestErrorMethods(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -211,94 +757,324 @@ func TestErrorOnUnexpectedStatusCode(t *testing.T) {
 	}
 }
 
-func TestTextToSpeech(t *testing.T) {
+func TestErrorOnNonJSONBody(t *testing.T) {
+	gatewayPage := "<html>\n<head><title>502 Bad Gateway</title></head>\n<body>\n<center><h1>502 Bad Gateway</h1></center>\n</body>\n</html>\n"
 	testCases := []struct {
-		name               string
-		excludeAPIKey      bool
-		queries            []elevenlabs.QueryFunc
-		expQueryString     string
-		testRequestBody    any
-		expResponseBody    []byte
-		expectedRespStatus int
+		name    string
+		status  int
+		body    string
+		expBody string
 	}{
 		{
-			name:          "No API key and no queries",
-			excludeAPIKey: true,
-			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
-				Text:    "Test text",
-			},
-			expResponseBody:    testRespBodies["TestTextToSpeech"],
-			expectedRespStatus: http.StatusOK,
+			name:    "HTML from a gateway",
+			status:  http.StatusBadGateway,
+			body:    gatewayPage,
+			expBody: "<html> <head><title>502 Bad Gateway</title></head> <body> <center><h1>502 Bad Gateway</h1></center> </body> </html>",
 		},
 		{
-			name:           "With API key and latency optimizations query",
-			excludeAPIKey:  false,
-			queries:        []elevenlabs.QueryFunc{elevenlabs.LatencyOptimizations(2)},
-			expQueryString: "optimize_streaming_latency=2",
-			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
-				Text:    "Test text",
-			},
-			expResponseBody:    testRespBodies["TestTextToSpeech"],
-			expectedRespStatus: http.StatusOK,
+			name:    "truncated API error",
+			status:  http.StatusUnauthorized,
+			body:    `{"detail":{"status":"invalid_api_key","mess`,
+			expBody: `{"detail":{"status":"invalid_api_key","mess`,
 		},
 		{
-			name:           "With API key and output format query",
-			excludeAPIKey:  false,
-			queries:        []elevenlabs.QueryFunc{elevenlabs.OutputFormat("ulaw_8000")},
-			expQueryString: "output_format=ulaw_8000",
-			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
-				Text:    "Test text",
-			},
-			expResponseBody:    testRespBodies["TestTextToSpeech"],
-			expectedRespStatus: http.StatusOK,
+			name:    "truncated validation error",
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"detail":[{"loc":["body","text"],`,
+			expBody: `{"detail":[{"loc":["body","text"],`,
 		},
 		{
-			name:           "With API key and latency optimizations and output format queries",
-			excludeAPIKey:  false,
-			queries:        []elevenlabs.QueryFunc{elevenlabs.LatencyOptimizations(3), elevenlabs.OutputFormat("mp3_44100_32")},
-			expQueryString: "optimize_streaming_latency=3&output_format=mp3_44100_32",
-			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
-				Text:    "Test text",
-			},
-			expResponseBody:    testRespBodies["TestTextToSpeech"],
-			expectedRespStatus: http.StatusOK,
+			name:    "long body",
+			status:  http.StatusBadGateway,
+			body:    strings.Repeat("x", 1000),
+			expBody: strings.Repeat("x", 200) + "…",
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			requestAPIKey := mockAPIKey
-			if tc.excludeAPIKey {
-				requestAPIKey = ""
-			}
 			server := testServer(t, testServerConfig{
-				keyOptional:         tc.excludeAPIKey,
-				expectedMethod:      http.MethodPost,
-				expectedContentType: contentTypeJSON,
-				expectedQueryStr:    tc.expQueryString,
-				statusCode:          tc.expectedRespStatus,
-				responseBody:        tc.expResponseBody,
+				expectedMethod: http.MethodGet,
+				statusCode:     tc.status,
+				responseBody:   []byte(tc.body),
 			})
 			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			_, err := client.GetModels()
+			var statusErr *elevenlabs.UnexpectedStatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("Expected an *UnexpectedStatusError, got %T: %v", err, err)
+			}
+			if statusErr.StatusCode() != tc.status {
+				t.Errorf("Expected status code %d, got %d", tc.status, statusErr.StatusCode())
+			}
+			if statusErr.Body != tc.expBody {
+				t.Errorf("Expected body %q, got %q", tc.expBody, statusErr.Body)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprint(tc.status)) || !strings.Contains(err.Error(), fmt.Sprintf("%q", tc.expBody)) {
+				t.Errorf("Expected error to contain the status and body, got %q", err)
+			}
+		})
+	}
+}
 
-			client := elevenlabs.NewMockClient(context.Background(), server.URL, requestAPIKey, mockTimeout)
-			respBody, err := client.TextToSpeech("voiceID", tc.testRequestBody.(elevenlabs.TextToSpeechRequest), tc.queries...)
-
-			if err != nil {
-				t.Errorf("Expected no errors, got error: %q", err)
-			}
-
-			if string(respBody) != string(tc.expResponseBody) {
-				t.Errorf("Expected response %q, got %q", string(tc.expResponseBody), string(respBody))
+func TestErrQuotaExceeded(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := testServer(t, testServerConfig{
+				expectedMethod: http.MethodPost,
+				statusCode:     status,
+				responseBody:   testRespBodies["TestErrQuotaExceeded"],
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			_, err := client.TextToSpeech("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			if !errors.Is(err, elevenlabs.ErrQuotaExceeded) {
+				t.Errorf("Expected an error matching ErrQuotaExceeded, got %T: %v", err, err)
+			}
+			if errors.Is(err, elevenlabs.ErrNotFound) {
+				t.Errorf("Expected the error not to match ErrNotFound, got %v", err)
 			}
 		})
 	}
+
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodPost,
+		statusCode:     http.StatusUnauthorized,
+		responseBody:   testRespBodies["TestAPIErrorOnBadRequestAndUnauthorized"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	_, err := client.TextToSpeech("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+	if err == nil || errors.Is(err, elevenlabs.ErrQuotaExceeded) {
+		t.Errorf("Expected an error not matching ErrQuotaExceeded, got %v", err)
+	}
 }
 
-func TestTextToSpeechStream(t *testing.T) {
+func TestMaxResponseBytes(t *testing.T) {
+	oversized := `{"voices":[` + strings.Repeat(`{"voice_id":"TestVoiceID"},`, 100) + `{}]}`
+	testCases := []struct {
+		name        string
+		contentType string
+		status      int
+		body        string
+		expErr      error
+	}{
+		{name: "JSON body within the limit", contentType: contentTypeJSON, status: http.StatusOK, body: `{"voices":[]}`},
+		{name: "oversized JSON body", contentType: contentTypeJSON, status: http.StatusOK, body: oversized, expErr: elevenlabs.ErrResponseTooLarge},
+		{name: "oversized error body", contentType: contentTypeJSON, status: http.StatusNotFound, body: oversized, expErr: elevenlabs.ErrNotFound},
+		{name: "audio body not limited", contentType: "audio/mpeg", status: http.StatusOK, body: strings.Repeat("audio", 1000)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithMaxResponseBytes(1024))
+
+			var err error
+			if tc.contentType == contentTypeJSON {
+				_, err = client.GetVoices()
+			} else {
+				var audio []byte
+				audio, err = client.TextToSpeech("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+				if err == nil && string(audio) != tc.body {
+					t.Errorf("Expected the whole audio of %d bytes, got %d bytes", len(tc.body), len(audio))
+				}
+			}
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %v, got %v", tc.expErr, err)
+			}
+			var sc elevenlabs.StatusCoder
+			if tc.status != http.StatusOK && (!errors.As(err, &sc) || sc.StatusCode() != tc.status) {
+				t.Errorf("Expected an error with status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+func TestRequestDumps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text-to-speech/failingVoiceID" {
+			w.Header().Set("Content-Type", contentTypeJSON)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write(testRespBodies["TestValidationErrorFields"])
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("Test audio"))
+	}))
+	defer server.Close()
+	var dumps bytes.Buffer
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithRequestDumps(&dumps))
+	if got := client.LastRequestDump(); got != "" {
+		t.Errorf("Expected no dump before any request, got %q", got)
+	}
+
+	if _, err := client.TextToSpeech("failingVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}); err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	dump := client.LastRequestDump()
+	for _, s := range []string{"POST /text-to-speech/failingVoiceID", "Xi-Api-Key: [REDACTED]", `  "text": "Test text"`, "HTTP/1.1 422 Unprocessable Entity", `"msg": "field required"`} {
+		if !strings.Contains(dump, s) {
+			t.Errorf("Expected the dump to contain %q, got:\n%s", s, dump)
+		}
+	}
+	if strings.Contains(dump, mockAPIKey) {
+		t.Errorf("Expected the API key to be redacted, got:\n%s", dump)
+	}
+
+	if _, err := client.TextToSpeech("voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	dump = client.LastRequestDump()
+	if !strings.Contains(dump, "POST /text-to-speech/voiceID") || !strings.Contains(dump, "[10 bytes of audio/mpeg]") || strings.Contains(dump, "Test audio") {
+		t.Errorf("Expected the dump of the last request without its audio, got:\n%s", dump)
+	}
+	if n := strings.Count(dumps.String(), ">>> HTTP REQUEST >>>"); n != 2 {
+		t.Errorf("Expected the dumps of both requests to be written, got %d:\n%s", n, dumps.String())
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusServiceUnavailable
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	setStatus := func(s int) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = s
+	}
+	getHits := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+
+	cooldown := 100 * time.Millisecond
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithCircuitBreaker(2, cooldown))
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetModels(); err == nil || errors.Is(err, elevenlabs.ErrCircuitOpen) {
+			t.Fatalf("Expected the API error for request %d, got %v", i+1, err)
+		}
+	}
+	if _, err := client.With(elevenlabs.WithTimeout(time.Second)).GetModels(); !errors.Is(err, elevenlabs.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen once the threshold is reached, got %v", err)
+	}
+	if n := getHits(); n != 2 {
+		t.Errorf("Expected no request to be sent while the circuit is open, got %d requests", n)
+	}
+
+	time.Sleep(cooldown + 20*time.Millisecond)
+	if _, err := client.GetModels(); err == nil || errors.Is(err, elevenlabs.ErrCircuitOpen) {
+		t.Fatalf("Expected a request to be sent after the cooldown, got %v", err)
+	}
+	if _, err := client.GetModels(); !errors.Is(err, elevenlabs.ErrCircuitOpen) {
+		t.Fatalf("Expected the circuit to open again after a failure following the cooldown, got %v", err)
+	}
+
+	time.Sleep(cooldown + 20*time.Millisecond)
+	setStatus(http.StatusOK)
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors once the API recovered, got error: %q", err)
+	}
+	setStatus(http.StatusBadRequest)
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetModels(); errors.Is(err, elevenlabs.ErrCircuitOpen) {
+			t.Fatalf("Expected client errors not to open the circuit, got %v", err)
+		}
+	}
+}
+
+func TestErrorStatusCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		respBody []byte
+	}{
+		{name: "API error", status: http.StatusUnauthorized, respBody: testRespBodies["TestAPIErrorOnBadRequestAndUnauthorized"]},
+		{name: "validation error", status: http.StatusUnprocessableEntity, respBody: testRespBodies["TestValidationErrorOnUnprocessableEntity"]},
+		{name: "unexpected status", status: http.StatusServiceUnavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testServer(t, testServerConfig{
+				expectedMethod: http.MethodGet,
+				statusCode:     tc.status,
+				responseBody:   tc.respBody,
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			_, err := client.GetModels()
+			var sc elevenlabs.StatusCoder
+			if !errors.As(err, &sc) {
+				t.Fatalf("Expected error implementing StatusCoder, got %T: %v", err, err)
+			}
+			if sc.StatusCode() != tc.status {
+				t.Errorf("Expected status code %d, got %d", tc.status, sc.StatusCode())
+			}
+		})
+	}
+}
+
+func TestErrNotFound(t *testing.T) {
+	respBody := []byte(`{"detail":{"status":"not_found","message":"The requested resource was not found."}}`)
+	testCases := []struct {
+		name string
+		call func(c *elevenlabs.Client) error
+	}{
+		{name: "GetVoice", call: func(c *elevenlabs.Client) error {
+			_, err := c.GetVoice("DeletedVoiceID")
+			return err
+		}},
+		{name: "GetHistoryItem", call: func(c *elevenlabs.Client) error {
+			_, err := c.GetHistoryItem("DeletedItemID")
+			return err
+		}},
+		{name: "GetSampleAudio", call: func(c *elevenlabs.Client) error {
+			_, err := c.GetSampleAudio("TestVoiceID", "DeletedSampleID")
+			return err
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testServer(t, testServerConfig{
+				expectedMethod: http.MethodGet,
+				statusCode:     http.StatusNotFound,
+				responseBody:   respBody,
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			err := tc.call(client)
+			if !errors.Is(err, elevenlabs.ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound, got %T: %v", err, err)
+			}
+			if !strings.Contains(err.Error(), "The requested resource was not found.") {
+				t.Errorf("Expected error message to contain the API message, got %q", err)
+			}
+		})
+	}
+
+	server := testServer(t, testServerConfig{expectedMethod: http.MethodGet, statusCode: http.StatusInternalServerError})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	if _, err := client.GetVoice("TestVoiceID"); errors.Is(err, elevenlabs.ErrNotFound) {
+		t.Errorf("Expected a 500 error not to match ErrNotFound, got %v", err)
+	}
+}
+
+func TestTextToSpeech(t *testing.T) {
 	testCases := []struct {
 		name               string
 		excludeAPIKey      bool
@@ -312,20 +1088,46 @@ func TestTextToSpeechStream(t *testing.T) {
 			name:          "No API key and no queries",
 			excludeAPIKey: true,
 			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
+				ModelID: elevenlabs.ModelMultilingualV2,
 				Text:    "Test text",
 			},
-			expResponseBody:    testRespBodies["TestTextToSpeechStream"],
+			expResponseBody:    testRespBodies["TestTextToSpeech"],
 			expectedRespStatus: http.StatusOK,
 		},
 		{
-			name:          "With API key and no queries",
-			excludeAPIKey: false,
+			name:           "With API key and latency optimizations query",
+			excludeAPIKey:  false,
+			queries:        []elevenlabs.QueryFunc{elevenlabs.LatencyOptimizations(2)},
+			expQueryString: "optimize_streaming_latency=2",
 			testRequestBody: elevenlabs.TextToSpeechRequest{
-				ModelID: "model1",
+				ModelID: elevenlabs.ModelMultilingualV2,
 				Text:    "Test text",
 			},
-			expResponseBody:    testRespBodies["TestTextToSpeechStream"],
+			expResponseBody:    testRespBodies["TestTextToSpeech"],
+			expectedRespStatus: http.StatusOK,
+		},
+		{
+			name:           "With API key and output format query",
+			excludeAPIKey:  false,
+			queries:        []elevenlabs.QueryFunc{elevenlabs.OutputFormat("ulaw_8000")},
+			expQueryString: "output_format=ulaw_8000",
+			testRequestBody: elevenlabs.TextToSpeechRequest{
+				ModelID: elevenlabs.ModelMultilingualV2,
+				Text:    "Test text",
+			},
+			expResponseBody:    testRespBodies["TestTextToSpeech"],
+			expectedRespStatus: http.StatusOK,
+		},
+		{
+			name:           "With API key and latency optimizations and output format queries",
+			excludeAPIKey:  false,
+			queries:        []elevenlabs.QueryFunc{elevenlabs.LatencyOptimizations(3), elevenlabs.OutputFormat("mp3_44100_32")},
+			expQueryString: "optimize_streaming_latency=3&output_format=mp3_44100_32",
+			testRequestBody: elevenlabs.TextToSpeechRequest{
+				ModelID: elevenlabs.ModelMultilingualV2,
+				Text:    "Test text",
+			},
+			expResponseBody:    testRespBodies["TestTextToSpeech"],
 			expectedRespStatus: http.StatusOK,
 		},
 	}
@@ -346,387 +1148,3706 @@ func TestTextToSpeechStream(t *testing.T) {
 			defer server.Close()
 
 			client := elevenlabs.NewMockClient(context.Background(), server.URL, requestAPIKey, mockTimeout)
-			w := bytes.Buffer{}
-			err := client.TextToSpeechStream(&w, "voiceID", tc.testRequestBody.(elevenlabs.TextToSpeechRequest), tc.queries...)
+			if tc.excludeAPIKey {
+				client = client.With(elevenlabs.WithoutAPIKey())
+			}
+			respBody, err := client.TextToSpeech("voiceID", tc.testRequestBody.(elevenlabs.TextToSpeechRequest), tc.queries...)
+
 			if err != nil {
 				t.Errorf("Expected no errors, got error: %q", err)
 			}
-			respBody := w.Bytes()
 
-			if string(respBody) != string(tc.expResponseBody) {
-				t.Errorf("Expected response %q, got %q", string(tc.expResponseBody), string(respBody))
-			}
-		})
+			if string(respBody) != string(tc.expResponseBody) {
+				t.Errorf("Expected response %q, got %q", string(tc.expResponseBody), string(respBody))
+			}
+		})
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	requests := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, "", mockTimeout)
+
+	if _, err := client.GetModels(); !errors.Is(err, elevenlabs.ErrMissingAPIKey) {
+		t.Errorf("Expected ErrMissingAPIKey, got %v", err)
+	}
+	select {
+	case <-requests:
+		t.Error("Expected no request to be sent without an API key")
+	default:
+	}
+	if _, err := client.With(elevenlabs.WithoutAPIKey()).GetModels(); err != nil {
+		t.Errorf("Expected no errors with WithoutAPIKey, got error: %q", err)
+	}
+	if err := client.TextToSpeechInputStream(make(chan string), make(chan elevenlabs.StreamingOutputResponse), io.Discard, "voiceID", elevenlabs.ModelTurboV2, elevenlabs.TextToSpeechInputStreamingRequest{}); !errors.Is(err, elevenlabs.ErrMissingAPIKey) {
+		t.Errorf("Expected ErrMissingAPIKey from TextToSpeechInputStream, got %v", err)
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{elevenlabs.FormatMP3_44100_128, elevenlabs.FormatPCM_16000, elevenlabs.FormatULaw_8000, elevenlabs.FormatOpus_48000_64} {
+		if err := elevenlabs.ValidateOutputFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got error: %q", format, err)
+		}
+	}
+	for _, format := range []string{"mp3_44100_129", "wav", "", "PCM_16000"} {
+		if err := elevenlabs.ValidateOutputFormat(format); err == nil {
+			t.Errorf("Expected %q to be invalid, got nil", format)
+		}
+	}
+
+	if _, err := elevenlabs.ValidatedOutputFormat("mp3_44100_129"); err == nil {
+		t.Error("Expected ValidatedOutputFormat to reject an unknown format, got nil")
+	}
+	qf, err := elevenlabs.ValidatedOutputFormat(elevenlabs.FormatPCM_24000)
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	q := url.Values{}
+	qf(&q)
+	if got := q.Get("output_format"); got != elevenlabs.FormatPCM_24000 {
+		t.Errorf("Expected output_format %q, got %q", elevenlabs.FormatPCM_24000, got)
+	}
+}
+
+func TestSupportedOutputFormats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		endpoint string
+		tier     string
+		format   string
+		expValid bool
+	}{
+		{name: "wav emulated by text-to-speech", endpoint: elevenlabs.EndpointTextToSpeech, format: elevenlabs.FormatWAV_16000, expValid: true},
+		{name: "wav not streamed", endpoint: elevenlabs.EndpointTextToSpeechStream, format: elevenlabs.FormatWAV_16000},
+		{name: "wav not supported by stream-input", endpoint: elevenlabs.EndpointTextToSpeechInputStream, format: elevenlabs.FormatWAV_44100},
+		{name: "pcm supported by stream-input", endpoint: elevenlabs.EndpointTextToSpeechInputStream, format: elevenlabs.FormatPCM_16000, expValid: true},
+		{name: "192kbps without tier", endpoint: elevenlabs.EndpointTextToSpeech, format: elevenlabs.FormatMP3_44100_192, expValid: true},
+		{name: "192kbps on free tier", endpoint: elevenlabs.EndpointTextToSpeech, tier: elevenlabs.TierFree, format: elevenlabs.FormatMP3_44100_192},
+		{name: "192kbps on creator tier", endpoint: elevenlabs.EndpointTextToSpeech, tier: elevenlabs.TierCreator, format: elevenlabs.FormatMP3_44100_192, expValid: true},
+		{name: "pcm_44100 on creator tier", endpoint: elevenlabs.EndpointTextToSpeechStream, tier: elevenlabs.TierCreator, format: elevenlabs.FormatPCM_44100},
+		{name: "pcm_44100 on former tier name", endpoint: elevenlabs.EndpointTextToSpeechStream, tier: "independent_publisher", format: elevenlabs.FormatPCM_44100, expValid: true},
+		{name: "unknown format", endpoint: elevenlabs.EndpointTextToSpeech, format: "mp3_44100_129"},
+		{name: "unknown endpoint", endpoint: "sound-generation", format: elevenlabs.FormatMP3_44100_128},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := elevenlabs.ValidateOutputFormatFor(tc.endpoint, tc.tier, tc.format)
+			if tc.expValid && err != nil {
+				t.Errorf("Expected %q to be supported, got error: %q", tc.format, err)
+			}
+			if !tc.expValid && err == nil {
+				t.Errorf("Expected %q not to be supported, got nil", tc.format)
+			}
+		})
+	}
+
+	if formats := elevenlabs.SupportedOutputFormats("sound-generation", ""); formats != nil {
+		t.Errorf("Expected no formats for an unknown endpoint, got %v", formats)
+	}
+	formats := elevenlabs.SupportedOutputFormats(elevenlabs.EndpointTextToSpeechStream, elevenlabs.TierFree)
+	if len(formats) == 0 || formats[0] != elevenlabs.FormatMP3_22050_32 {
+		t.Errorf("Expected the formats in order starting with %q, got %v", elevenlabs.FormatMP3_22050_32, formats)
+	}
+}
+
+func TestTextToSpeechLong(t *testing.T) {
+	var gotRequests []elevenlabs.TextToSpeechRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req elevenlabs.TextToSpeechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		gotRequests = append(gotRequests, req)
+		w.Header().Set("request-id", fmt.Sprintf("req%d", len(gotRequests)))
+		w.Write([]byte(fmt.Sprintf("audio%d;", len(gotRequests))))
+	}))
+	defer server.Close()
+
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	text := "First sentence. Second sentence. Third sentence. Fourth sentence. Fifth sentence."
+	audio, err := client.TextToSpeechLong("voiceID", text, elevenlabs.TextToSpeechRequest{ModelID: elevenlabs.ModelMultilingualV2}, 20)
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if expAudio := "audio1;audio2;audio3;audio4;audio5;"; string(audio) != expAudio {
+		t.Errorf("Expected concatenated audio %q, got %q", expAudio, audio)
+	}
+	if len(gotRequests) != 5 {
+		t.Fatalf("Expected 5 requests, got %d", len(gotRequests))
+	}
+	last := gotRequests[4]
+	if last.Text != "Fifth sentence." || last.PreviousText != "Fourth sentence." || last.NextText != "" {
+		t.Errorf("Unexpected texts in last request: %+v", last)
+	}
+	if expIds := []string{"req2", "req3", "req4"}; !reflect.DeepEqual(expIds, last.PreviousRequestIds) {
+		t.Errorf("Expected previous request IDs %q, got %q", expIds, last.PreviousRequestIds)
+	}
+	if gotRequests[0].ModelID != elevenlabs.ModelMultilingualV2 || gotRequests[0].NextText != "Second sentence." {
+		t.Errorf("Unexpected first request: %+v", gotRequests[0])
+	}
+}
+
+func TestBuildTextToSpeechRequest(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodPost,
+		expectedContentType: contentTypeJSON,
+		expectedQueryStr:    "output_format=pcm_16000",
+		statusCode:          http.StatusOK,
+		responseBody:        testRespBodies["TestTextToSpeech"],
+	})
+	defer server.Close()
+
+	ttsReq := elevenlabs.TextToSpeechRequest{ModelID: elevenlabs.ModelMultilingualV2, Text: "Test text"}
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	req, err := client.BuildTextToSpeechRequest("TestVoiceID", ttsReq, elevenlabs.OutputFormat("pcm_16000"))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+
+	expURL := server.URL + "/text-to-speech/TestVoiceID?output_format=pcm_16000"
+	if req.Method != http.MethodPost || req.URL.String() != expURL {
+		t.Errorf("Expected request %s %s, got %s %s", http.MethodPost, expURL, req.Method, req.URL)
+	}
+	if req.Header.Get("xi-api-key") != mockAPIKey {
+		t.Errorf("Expected API key header %q, got %q", mockAPIKey, req.Header.Get("xi-api-key"))
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("Expected no errors getting the body, got error: %q", err)
+	}
+	var gotReq elevenlabs.TextToSpeechRequest
+	if err := json.NewDecoder(body).Decode(&gotReq); err != nil {
+		t.Fatalf("Failed to decode request body: %s", err)
+	}
+	if !reflect.DeepEqual(gotReq, ttsReq) {
+		t.Errorf("Expected request body %+v, got %+v", ttsReq, gotReq)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected the prepared request to be sent without errors, got error: %q", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestBuildTextToSpeechRequestWAV(t *testing.T) {
+	client := elevenlabs.NewMockClient(context.Background(), "http://localhost:1234", mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{ModelID: elevenlabs.ModelMultilingualV2, Text: "Test text"}
+
+	req, err := client.BuildTextToSpeechRequest("TestVoiceID", ttsReq, elevenlabs.OutputFormat(elevenlabs.FormatWAV_44100))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := req.URL.Query().Get("output_format"); got != elevenlabs.FormatPCM_44100 {
+		t.Errorf("Expected the wav format to be requested as %q, got %q", elevenlabs.FormatPCM_44100, got)
+	}
+
+	if _, err := client.BuildTextToSpeechStreamRequest("TestVoiceID", ttsReq, elevenlabs.OutputFormat(elevenlabs.FormatWAV_44100)); err == nil {
+		t.Error("Expected an error for a wav format on the stream endpoint, got nil")
+	}
+}
+
+func TestTextToSpeechBatch(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req elevenlabs.TextToSpeechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		mu.Lock()
+		requested = append(requested, req.Text)
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		if req.Text == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("audio:" + req.Text))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{ModelID: elevenlabs.ModelMultilingualV2}
+
+	texts := []string{"one", "two", "fail", "four", "five", "six", "seven"}
+	audio, err := client.TextToSpeechBatch("TestVoiceID", texts, ttsReq, 3)
+	if err == nil || !strings.Contains(err.Error(), "text 2") {
+		t.Errorf("Expected an error for text 2, got %v", err)
+	}
+	for i, text := range texts {
+		exp := "audio:" + text
+		if text == "fail" {
+			exp = ""
+		}
+		if string(audio[i]) != exp {
+			t.Errorf("Expected audio %q for text %d, got %q", exp, i, audio[i])
+		}
+	}
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", maxInFlight)
+	}
+
+	requested = nil
+	audio, err = client.With(elevenlabs.WithBatchFailFast()).TextToSpeechBatch("TestVoiceID", []string{"one", "fail", "three", "four"}, ttsReq, 1)
+	if err == nil {
+		t.Error("Expected an error, got nil")
+	}
+	if exp := []string{"one", "fail"}; !reflect.DeepEqual(exp, requested) {
+		t.Errorf("Expected only %q to be requested, got %q", exp, requested)
+	}
+	if audio[2] != nil || audio[3] != nil {
+		t.Errorf("Expected no audio for the texts that were not converted, got %q", audio)
+	}
+}
+
+func TestTextToSpeechWithContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := "audio/mpeg"
+		if strings.HasPrefix(r.URL.Query().Get("output_format"), "pcm_") {
+			contentType = "audio/pcm"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	testCases := []struct {
+		format         string
+		expContentType string
+	}{
+		{format: elevenlabs.FormatMP3_44100_128, expContentType: "audio/mpeg"},
+		{format: elevenlabs.FormatPCM_16000, expContentType: "audio/pcm"},
+		{format: elevenlabs.FormatWAV_16000, expContentType: "audio/wav"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			audio, contentType, err := client.TextToSpeechWithContentType("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}, elevenlabs.OutputFormat(tc.format))
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if len(audio) == 0 {
+				t.Error("Expected audio to be returned")
+			}
+			if contentType != tc.expContentType {
+				t.Errorf("Expected content type %q, got %q", tc.expContentType, contentType)
+			}
+		})
+	}
+}
+
+func TestTextToSpeechStream(t *testing.T) {
+	testCases := []struct {
+		name               string
+		excludeAPIKey      bool
+		queries            []elevenlabs.QueryFunc
+		expQueryString     string
+		testRequestBody    any
+		expResponseBody    []byte
+		expectedRespStatus int
+	}{
+		{
+			name:          "No API key and no queries",
+			excludeAPIKey: true,
+			testRequestBody: elevenlabs.TextToSpeechRequest{
+				ModelID: elevenlabs.ModelMultilingualV2,
+				Text:    "Test text",
+			},
+			expResponseBody:    testRespBodies["TestTextToSpeechStream"],
+			expectedRespStatus: http.StatusOK,
+		},
+		{
+			name:          "With API key and no queries",
+			excludeAPIKey: false,
+			testRequestBody: elevenlabs.TextToSpeechRequest{
+				ModelID: elevenlabs.ModelMultilingualV2,
+				Text:    "Test text",
+			},
+			expResponseBody:    testRespBodies["TestTextToSpeechStream"],
+			expectedRespStatus: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requestAPIKey := mockAPIKey
+			if tc.excludeAPIKey {
+				requestAPIKey = ""
+			}
+			server := testServer(t, testServerConfig{
+				keyOptional:         tc.excludeAPIKey,
+				expectedMethod:      http.MethodPost,
+				expectedContentType: contentTypeJSON,
+				expectedQueryStr:    tc.expQueryString,
+				statusCode:          tc.expectedRespStatus,
+				responseBody:        tc.expResponseBody,
+			})
+			defer server.Close()
+
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, requestAPIKey, mockTimeout)
+			if tc.excludeAPIKey {
+				client = client.With(elevenlabs.WithoutAPIKey())
+			}
+			w := bytes.Buffer{}
+			err := client.TextToSpeechStream(&w, "voiceID", tc.testRequestBody.(elevenlabs.TextToSpeechRequest), tc.queries...)
+			if err != nil {
+				t.Errorf("Expected no errors, got error: %q", err)
+			}
+			respBody := w.Bytes()
+
+			if string(respBody) != string(tc.expResponseBody) {
+				t.Errorf("Expected response %q, got %q", string(tc.expResponseBody), string(respBody))
+			}
+		})
+	}
+}
+
+func TestTextToSpeechStreamZeroVoiceSettings(t *testing.T) {
+	bodyCh := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		bodyCh <- body
+		w.Write(testRespBodies["TestTextToSpeechStream"])
+	}))
+	defer server.Close()
+
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{
+		Text: "Test text",
+		VoiceSettings: &elevenlabs.VoiceSettings{
+			Stability:       elevenlabs.Float32(0),
+			SimilarityBoost: elevenlabs.Float32(0.75),
+			Style:           elevenlabs.Float32(0),
+			SpeakerBoost:    elevenlabs.Bool(false),
+		},
+	}
+	if err := client.TextToSpeechStream(io.Discard, "voiceID", ttsReq); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expSettings := map[string]any{"stability": 0.0, "similarity_boost": 0.75, "style": 0.0, "use_speaker_boost": false}
+	if got := (<-bodyCh)["voice_settings"]; !reflect.DeepEqual(expSettings, got) {
+		t.Errorf("Expected voice settings %v to be sent, got %v", expSettings, got)
+	}
+}
+
+func TestTextToSpeechPipe(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		body       string
+		truncate   bool
+		expErr     error
+		expReadErr bool
+	}{
+		{name: "whole stream", status: http.StatusOK, body: "Test audio"},
+		{name: "API error", status: http.StatusNotFound, expErr: elevenlabs.ErrNotFound},
+		{name: "interrupted stream", status: http.StatusOK, body: "Test audio", truncate: true, expReadErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/text-to-speech/voiceID/stream" {
+					t.Errorf("Server: expected path %q, got %q", "/text-to-speech/voiceID/stream", r.URL.Path)
+				}
+				if tc.truncate {
+					// Announce more bytes than are sent, so that the stream ends unexpectedly.
+					w.Header().Set("Content-Length", fmt.Sprint(len(tc.body)*2))
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+			r, err := client.TextToSpeechPipe("voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expected error %v, got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			defer r.Close()
+			b, err := io.ReadAll(r)
+			if tc.expReadErr {
+				if err == nil {
+					t.Error("Expected the interruption to be returned by Read, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors reading the audio, got %q", err)
+			}
+			if string(b) != tc.body {
+				t.Errorf("Expected audio %q, got %q", tc.body, b)
+			}
+		})
+	}
+}
+
+func TestTextToSpeechStreamTimeout(t *testing.T) {
+	testCases := []struct {
+		name       string
+		firstDelay time.Duration
+		expErr     error
+	}{
+		{name: "stream outlasting the timeout"},
+		{name: "first bytes not received within the timeout", firstDelay: 300 * time.Millisecond, expErr: context.DeadlineExceeded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				time.Sleep(tc.firstDelay)
+				w.Write([]byte("first"))
+				w.(http.Flusher).Flush()
+				time.Sleep(300 * time.Millisecond)
+				w.Write([]byte("second"))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, 100*time.Millisecond)
+			w := bytes.Buffer{}
+			err := client.TextToSpeechStream(&w, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("Expected error %v, got %v", tc.expErr, err)
+			}
+			if tc.expErr == nil && w.String() != "firstsecond" {
+				t.Errorf("Expected the whole stream %q, got %q", "firstsecond", w.String())
+			}
+		})
+	}
+}
+
+func TestTextToSpeechStreamCanceled(t *testing.T) {
+	testCases := []struct {
+		name        string
+		headerDelay time.Duration
+	}{
+		{name: "before the response", headerDelay: time.Second},
+		{name: "mid-stream"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(tc.headerDelay):
+				}
+				w.WriteHeader(http.StatusOK)
+				for {
+					if _, err := w.Write([]byte("chunk")); err != nil {
+						return
+					}
+					w.(http.Flusher).Flush()
+					select {
+					case <-r.Context().Done():
+						return
+					case <-time.After(10 * time.Millisecond):
+					}
+				}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			client := elevenlabs.NewMockClient(ctx, server.URL, mockAPIKey, mockTimeout)
+			pr, pw := io.Pipe()
+			go func() {
+				if tc.headerDelay > 0 {
+					time.Sleep(50 * time.Millisecond)
+				} else {
+					// Cancel once the stream is flowing.
+					io.ReadFull(pr, make([]byte, 5))
+				}
+				cancel()
+				io.Copy(io.Discard, pr)
+			}()
+			err := client.TextToSpeechStream(pw, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			pw.Close()
+			if err != context.Canceled {
+				t.Errorf("Expected context.Canceled, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestWithStreamIdleTimeout(t *testing.T) {
+	testCases := []struct {
+		name   string
+		gap    time.Duration
+		expErr error
+	}{
+		{name: "data flowing", gap: 50 * time.Millisecond},
+		{name: "stalled stream", gap: 400 * time.Millisecond, expErr: context.DeadlineExceeded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				for i := 0; i < 4; i++ {
+					w.Write([]byte("chunk"))
+					w.(http.Flusher).Flush()
+					time.Sleep(tc.gap)
+				}
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(elevenlabs.WithStreamIdleTimeout(150 * time.Millisecond))
+			w := bytes.Buffer{}
+			err := client.TextToSpeechStream(&w, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("Expected error %v, got %v", tc.expErr, err)
+			}
+			if tc.expErr == nil && w.String() != strings.Repeat("chunk", 4) {
+				t.Errorf("Expected the whole stream %q, got %q", strings.Repeat("chunk", 4), w.String())
+			}
+		})
+	}
+}
+
+func TestTextToSpeechInputStreamIdleTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		// Receive the text but never reply.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				break
+			}
+		}
+		close(done)
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithStreamIdleTimeout(100 * time.Millisecond))
+	err := client.TextToSpeechInputStream(sendText("Hello "), nil, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded error, got %v", err)
+	}
+	<-done
+}
+
+func TestTextToSpeechInputStream(t *testing.T) {
+	textsCh := make(chan []string, 1)
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		textsCh <- serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+	audio := bytes.Buffer{}
+	err := client.TextToSpeechInputStream(sendText("Hello ", "world "), responses, &audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if audio.String() != "audio" {
+		t.Errorf("Expected audio %q, got %q", "audio", audio.String())
+	}
+	if expTexts, gotTexts := []string{" ", "Hello ", "world ", ""}, <-textsCh; !reflect.DeepEqual(expTexts, gotTexts) {
+		t.Errorf("Expected server to receive texts %q, got %q", expTexts, gotTexts)
+	}
+	close(responses)
+	var final bool
+	for r := range responses {
+		final = r.IsFinal
+	}
+	if !final {
+		t.Error("Expected the last response to be final")
+	}
+}
+
+func TestTextToSpeechInputStreamQuery(t *testing.T) {
+	queryCh := make(chan url.Values, 1)
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queryCh <- r.URL.Query()
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		serveInputStream(t, conn, "audio")
+	}))
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+	err := client.TextToSpeechInputStream(sendText("Hello "), responses, &bytes.Buffer{}, "voiceID", "model&id", elevenlabs.TextToSpeechInputStreamingRequest{Text: " "}, elevenlabs.LatencyOptimizations(3), elevenlabs.OutputFormat(elevenlabs.FormatPCM_16000))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expQuery := url.Values{"model_id": {"model&id"}, "optimize_streaming_latency": {"3"}, "output_format": {"pcm_16000"}}
+	if gotQuery := <-queryCh; !reflect.DeepEqual(expQuery, gotQuery) {
+		t.Errorf("Expected query %v, got %v", expQuery, gotQuery)
+	}
+}
+
+func TestTextToSpeechInputStreamText(t *testing.T) {
+	frame := `{"audio":"YXVkaW8=","isFinal":false,` +
+		`"normalizedAlignment":{"charStartTimesMs":[0,3,7,9,11],"charDurationsMs":[3,4,2,2,4],"chars":["H","e","l","l","o"]},` +
+		`"alignment":{"charStartTimesMs":[0,3,7,9,11],"charDurationsMs":[3,4,2,2,4],"chars":["H","e","l","l","o"]}}`
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		for {
+			var msg map[string]any
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Errorf("Server: failed to read message: %s", err)
+				return
+			}
+			if msg["text"] == "" {
+				break
+			}
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		conn.WriteJSON(map[string]any{"isFinal": true})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+	err := client.TextToSpeechInputStream(sendText("Hello "), responses, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	close(responses)
+	first := <-responses
+	if first.Text != "Hello" {
+		t.Errorf("Expected response text %q, got %q", "Hello", first.Text)
+	}
+	if got := first.NormalizedAlignment.Text(); got != "Hello" {
+		t.Errorf("Expected normalized alignment text %q, got %q", "Hello", got)
+	}
+	if final := <-responses; !final.IsFinal || final.Text != "" {
+		t.Errorf("Expected a final response without text, got %+v", final)
+	}
+}
+
+func TestStreamingInputResponseDecoding(t *testing.T) {
+	frame := []byte(`{"audio":"YXVkaW8=","isFinal":true,` +
+		`"normalizedAlignment":{"charStartTimesMs":[0],"charDurationsMs":[3],"chars":["H"]},` +
+		`"alignment":{"charStartTimesMs":[0],"charDurationsMs":[3],"chars":["H"]}}`)
+	var resp elevenlabs.StreamingInputResponse
+	if err := json.Unmarshal(frame, &resp); err != nil {
+		t.Fatalf("Failed to unmarshal StreamingInputResponse: %s", err)
+	}
+	segment := elevenlabs.StreamingAlignmentSegment{CharStartTimesMs: []int{0}, CharDurationsMs: []int{3}, Chars: []string{"H"}}
+	exp := elevenlabs.StreamingInputResponse{Audio: "YXVkaW8=", IsFinal: true, NormalizedAlignment: segment, Alignment: segment}
+	if !reflect.DeepEqual(exp, resp) {
+		t.Errorf("Expected %+v, got %+v", exp, resp)
+	}
+}
+
+func TestTextToSpeechInputStreamRequestIds(t *testing.T) {
+	frames := []string{
+		`{"audio":"YXVkaW8=","seed":42,"request_id":"req1","next_request_ids":["req2","req3"]}`,
+		`{"audio":"YXVkaW8="}`,
+		`{"isFinal":true}`,
+	}
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		for {
+			var msg map[string]any
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Errorf("Server: failed to read message: %s", err)
+				return
+			}
+			if msg["text"] == "" {
+				break
+			}
+		}
+		for _, frame := range frames {
+			conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+	err := client.TextToSpeechInputStream(sendText("Hello "), responses, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	close(responses)
+	first := <-responses
+	if first.Seed == nil || *first.Seed != 42 {
+		t.Errorf("Expected seed 42, got %v", first.Seed)
+	}
+	if first.RequestId != "req1" || !reflect.DeepEqual([]string{"req2", "req3"}, first.NextRequestIds) {
+		t.Errorf("Expected request ID %q and next request IDs %q, got %q and %q", "req1", []string{"req2", "req3"}, first.RequestId, first.NextRequestIds)
+	}
+	if second := <-responses; second.Seed != nil || second.RequestId != "" || second.NextRequestIds != nil {
+		t.Errorf("Expected no seed or request IDs when absent, got %+v", second)
+	}
+}
+
+func TestTextToSpeechInputStreamRawBase64Audio(t *testing.T) {
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+
+	testCases := []struct {
+		name      string
+		client    *elevenlabs.Client
+		expAudio  string
+		expBase64 string
+	}{
+		{name: "decoded", client: client, expAudio: "audio"},
+		{name: "raw", client: client.With(elevenlabs.WithRawBase64Audio()), expBase64: "YXVkaW8="},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			responses := make(chan elevenlabs.StreamingOutputResponse, 10)
+			audio := bytes.Buffer{}
+			err := tc.client.TextToSpeechInputStream(sendText("Hello "), responses, &audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			close(responses)
+			if audio.String() != tc.expAudio {
+				t.Errorf("Expected audio %q to be written, got %q", tc.expAudio, audio.String())
+			}
+			if first := <-responses; first.AudioBase64 != tc.expBase64 {
+				t.Errorf("Expected base64 audio %q to be sent, got %q", tc.expBase64, first.AudioBase64)
+			}
+		})
+	}
+
+	err := client.With(elevenlabs.WithRawBase64Audio()).TextToSpeechInputStream(sendText("Hello "), nil, nil, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err == nil {
+		t.Error("Expected an error for raw base64 audio without a response channel, got nil")
+	}
+}
+
+func TestTextToSpeechInputStreamQueries(t *testing.T) {
+	queryCh := make(chan *url.URL, 1)
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queryCh <- r.URL
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		serveInputStream(t, conn, "audio")
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechInputStreamingRequest{Text: " "}
+
+	err := client.TextToSpeechInputStream(sendText("Hello "), nil, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, ttsReq,
+		elevenlabs.OutputFormat(elevenlabs.FormatPCM_16000), elevenlabs.LatencyOptimizations(3), elevenlabs.EnableLogging(false))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	u := <-queryCh
+	if u.Path != "/text-to-speech/voiceID/stream-input" {
+		t.Errorf("Expected path %q, got %q", "/text-to-speech/voiceID/stream-input", u.Path)
+	}
+	expQuery := "enable_logging=false&model_id=eleven_turbo_v2_5&optimize_streaming_latency=3&output_format=pcm_16000"
+	if u.RawQuery != expQuery {
+		t.Errorf("Expected query %q, got %q", expQuery, u.RawQuery)
+	}
+
+	testCases := []struct {
+		name    string
+		queries []elevenlabs.QueryFunc
+	}{
+		{name: "conflicting formats", queries: []elevenlabs.QueryFunc{elevenlabs.OutputFormat(elevenlabs.FormatPCM_16000), elevenlabs.OutputFormat(elevenlabs.FormatMP3_44100_128)}},
+		{name: "wav format", queries: []elevenlabs.QueryFunc{elevenlabs.OutputFormat(elevenlabs.FormatWAV_16000)}},
+		{name: "latency out of range", queries: []elevenlabs.QueryFunc{elevenlabs.LatencyOptimizations(5)}},
+		{name: "conflicting logging", queries: []elevenlabs.QueryFunc{elevenlabs.EnableLogging(true), elevenlabs.EnableLogging(false)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := client.TextToSpeechInputStream(sendText("Hello "), nil, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, ttsReq, tc.queries...)
+			if err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			select {
+			case u := <-queryCh:
+				t.Errorf("Expected no connection to be made, got a request to %s", u)
+			default:
+			}
+		})
+	}
+}
+
+func TestStreamProgress(t *testing.T) {
+	chunks := []string{"first", "second", "third"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		for _, chunk := range chunks {
+			w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	wsServer := testWSServer(t, func(conn *websocket.Conn, n int) {
+		serveInputStream(t, conn, "audio")
+	})
+	defer wsServer.Close()
+
+	var progress []elevenlabs.StreamProgress
+	opt := elevenlabs.WithStreamProgress(0, func(p elevenlabs.StreamProgress) {
+		progress = append(progress, p)
+	})
+	checkProgress := func(t *testing.T, expBytes int64) {
+		t.Helper()
+		if len(progress) < 2 {
+			t.Fatalf("Expected progress to be reported at least twice, got %+v", progress)
+		}
+		last := progress[len(progress)-1]
+		if !last.Done || last.Bytes != expBytes {
+			t.Errorf("Expected a last report with Done set and %d bytes, got %+v", expBytes, last)
+		}
+		for i, p := range progress[:len(progress)-1] {
+			if p.Done || p.Bytes == 0 || p.Bytes > expBytes || (i > 0 && p.Elapsed < progress[i-1].Elapsed) {
+				t.Errorf("Unexpected intermediate report %+v", p)
+			}
+		}
+	}
+
+	t.Run("TextToSpeechStream", func(t *testing.T) {
+		progress = nil
+		client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(opt)
+		if err := client.TextToSpeechStream(io.Discard, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}); err != nil {
+			t.Fatalf("Expected no errors, got error: %q", err)
+		}
+		checkProgress(t, int64(len(strings.Join(chunks, ""))))
+	})
+	t.Run("TextToSpeechInputStream", func(t *testing.T) {
+		progress = nil
+		client := elevenlabs.NewMockWSClient(context.Background(), wsURL(wsServer), mockAPIKey, mockTimeout).With(opt)
+		err := client.TextToSpeechInputStream(sendText("Hello "), nil, io.Discard, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+		if err != nil {
+			t.Fatalf("Expected no errors, got error: %q", err)
+		}
+		checkProgress(t, int64(len("audio")))
+	})
+}
+
+func TestStreamProgressStall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var progress []elevenlabs.StreamProgress
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout).With(
+		elevenlabs.WithStreamProgress(20*time.Millisecond, func(p elevenlabs.StreamProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p)
+		}))
+	if err := client.TextToSpeechStream(io.Discard, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	stalled := 0
+	for _, p := range progress {
+		if !p.Done && p.Bytes == int64(len("first")) {
+			stalled++
+		}
+	}
+	if stalled < 3 {
+		t.Errorf("Expected progress to be reported while the stream stalled, got %+v", progress)
+	}
+	if last := progress[len(progress)-1]; !last.Done || last.Bytes != int64(len("firstsecond")) {
+		t.Errorf("Expected a last report with Done set and %d bytes, got %+v", len("firstsecond"), last)
+	}
+	for _, p := range progress[:len(progress)-1] {
+		if p.Done {
+			t.Errorf("Unexpected report with Done set before the last one: %+v", p)
+		}
+	}
+}
+
+// cancelingWriter records the data written to it and calls cancel after the first write.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	defer w.cancel()
+	return w.Buffer.Write(p)
+}
+
+func TestStreamInterrupted(t *testing.T) {
+	t.Run("TextToSpeechStream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("first"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client := elevenlabs.NewMockClient(ctx, server.URL, mockAPIKey, mockTimeout)
+
+		audio := &cancelingWriter{cancel: cancel}
+		err := client.TextToSpeechStream(audio, "voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %T: %v", err, err)
+		}
+		if audio.String() != "first" {
+			t.Errorf("Expected the audio received before the interruption to be written, got %q", audio.String())
+		}
+	})
+
+	t.Run("TextToSpeechInputStream", func(t *testing.T) {
+		closeErr := make(chan error, 1)
+		server := testWSServer(t, func(conn *websocket.Conn, n int) {
+			conn.ReadJSON(&map[string]any{})
+			conn.ReadJSON(&map[string]any{})
+			conn.WriteJSON(map[string]any{"audio": base64.StdEncoding.EncodeToString([]byte("first"))})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					closeErr <- err
+					return
+				}
+			}
+		})
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client := elevenlabs.NewMockWSClient(ctx, wsURL(server), mockAPIKey, mockTimeout)
+
+		text := make(chan string, 1)
+		text <- "Hello "
+		audio := &cancelingWriter{cancel: cancel}
+		err := client.TextToSpeechInputStream(text, nil, audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %T: %v", err, err)
+		}
+		if audio.String() != "first" {
+			t.Errorf("Expected the audio received before the interruption to be written, got %q", audio.String())
+		}
+		select {
+		case err := <-closeErr:
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Errorf("Expected the connection to be closed with a close message, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("Expected the connection to be closed")
+		}
+	})
+}
+
+func TestTextToSpeechInputStreamReader(t *testing.T) {
+	textsCh := make(chan []string, 1)
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		textsCh <- serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	audio := bytes.Buffer{}
+	err := client.TextToSpeechInputStreamReader(strings.NewReader("Hello there, world."), &audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if audio.String() != "audio" {
+		t.Errorf("Expected audio %q, got %q", "audio", audio.String())
+	}
+	texts := <-textsCh
+	if got := strings.Join(texts, ""); got != " Hello there, world. " {
+		t.Errorf("Expected the text to be sent in full, got %q", texts)
+	}
+}
+
+func TestTextToSpeechInputStreamReaderCoalesce(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog"
+	textsCh := make(chan []string, 1)
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		textsCh <- serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithStreamCoalesce(15))
+	err := client.TextToSpeechInputStreamReader(strings.NewReader(text), io.Discard, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	texts := <-textsCh
+	// The initial and final messages frame the chunks of text.
+	expTexts := []string{" ", "The quick brown ", "fox jumps over ", "the lazy dog ", ""}
+	if !reflect.DeepEqual(expTexts, texts) {
+		t.Errorf("Expected the words to be sent as %q, got %q", expTexts, texts)
+	}
+
+	var chunks []string
+	for chunk := range elevenlabs.NewTextChunkerWithConfig(strings.NewReader(text), elevenlabs.ChunkerConfig{CoalesceChars: 15}) {
+		chunks = append(chunks, chunk)
+	}
+	if !reflect.DeepEqual(expTexts[1:4], chunks) {
+		t.Errorf("Expected NewTextChunkerWithConfig to return %q, got %q", expTexts[1:4], chunks)
+	}
+}
+
+func TestTextToSpeechInputStreamDialer(t *testing.T) {
+	var upgrader websocket.Upgrader
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		serveInputStream(t, conn, "audio")
+	}))
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	testCases := []struct {
+		name   string
+		opts   []elevenlabs.Option
+		expErr bool
+	}{
+		{name: "default dialer", expErr: true},
+		{name: "with dialer", opts: []elevenlabs.Option{elevenlabs.WithDialer(&websocket.Dialer{TLSClientConfig: tlsConfig})}},
+		{name: "with HTTP client", opts: []elevenlabs.Option{elevenlabs.WithHTTPClient(server.Client())}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(tc.opts...)
+			audio := bytes.Buffer{}
+			err := client.TextToSpeechInputStream(sendText("Hello "), nil, &audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+			if tc.expErr {
+				if err == nil {
+					t.Error("Expected an error for the untrusted certificate, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if audio.String() != "audio" {
+				t.Errorf("Expected audio %q, got %q", "audio", audio.String())
+			}
+		})
+	}
+}
+
+func TestTextToSpeechInputStreamVoiceSettings(t *testing.T) {
+	firstMsgCh := make(chan map[string]any, 1)
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Errorf("Server: failed to read message: %s", err)
+		}
+		firstMsgCh <- msg
+		serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	req := elevenlabs.TextToSpeechInputStreamingRequest{
+		Text: " ",
+		VoiceSettings: &elevenlabs.VoiceSettings{
+			Stability:       elevenlabs.Float32(0.5),
+			SimilarityBoost: elevenlabs.Float32(0.75),
+			Style:           elevenlabs.Float32(0.25),
+			SpeakerBoost:    elevenlabs.Bool(true),
+		},
+	}
+	err := client.TextToSpeechInputStream(sendText("Hello "), nil, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, req)
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expSettings := map[string]any{"stability": 0.5, "similarity_boost": 0.75, "style": 0.25, "use_speaker_boost": true}
+	if gotSettings := (<-firstMsgCh)["voice_settings"]; !reflect.DeepEqual(expSettings, gotSettings) {
+		t.Errorf("Expected the initial message to contain voice settings %v, got %v", expSettings, gotSettings)
+	}
+}
+
+func TestTextToSpeechInputStreamReconnect(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reconnects int
+		expError   bool
+	}{
+		{name: "without reconnect", reconnects: 0, expError: true},
+		{name: "with reconnect", reconnects: 1, expError: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textsCh := make(chan []string, 1)
+			server := testWSServer(t, func(conn *websocket.Conn, n int) {
+				if n == 0 {
+					// Drop the first connection right after the initial message.
+					var msg map[string]any
+					conn.ReadJSON(&msg)
+					return
+				}
+				textsCh <- serveInputStream(t, conn, "audio")
+			})
+			defer server.Close()
+
+			client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithStreamReconnect(tc.reconnects))
+			audio := bytes.Buffer{}
+			err := client.TextToSpeechInputStream(sendText("Hello ", "world "), nil, &audio, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+			if tc.expError {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if audio.String() != "audio" {
+				t.Errorf("Expected audio %q, got %q", "audio", audio.String())
+			}
+			if gotTexts := <-textsCh; len(gotTexts) < 2 || gotTexts[0] != " " || gotTexts[len(gotTexts)-1] != "" {
+				t.Errorf("Expected the initial request to be replayed and the stream to be flushed, got texts %q", gotTexts)
+			}
+		})
+	}
+}
+
+func TestTextToSpeechInputStreamEndedEarly(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reconnects int
+	}{
+		{name: "without reconnect"},
+		{name: "with reconnect", reconnects: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reconnected := make(chan struct{})
+			textsCh := make(chan []string, 1)
+			server := testWSServer(t, func(conn *websocket.Conn, n int) {
+				if n == 0 {
+					// End the first session after the first chunk of text, while more text is to come.
+					var msg map[string]any
+					conn.ReadJSON(&msg)
+					conn.ReadJSON(&msg)
+					conn.WriteJSON(map[string]any{"isFinal": true})
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+				close(reconnected)
+				textsCh <- serveInputStream(t, conn, "audio")
+			})
+			defer server.Close()
+
+			text := make(chan string, 1)
+			text <- "Hello "
+			if tc.reconnects > 0 {
+				go func() {
+					<-reconnected
+					text <- "world "
+					close(text)
+				}()
+			}
+			client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithStreamReconnect(tc.reconnects))
+			err := client.TextToSpeechInputStream(text, nil, io.Discard, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+			if tc.reconnects == 0 {
+				if !errors.Is(err, elevenlabs.ErrStreamEndedEarly) {
+					t.Errorf("Expected ErrStreamEndedEarly, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if expTexts, gotTexts := []string{" ", "world ", ""}, <-textsCh; !reflect.DeepEqual(expTexts, gotTexts) {
+				t.Errorf("Expected the remaining text %q to be sent after reconnecting, got %q", expTexts, gotTexts)
+			}
+		})
+	}
+}
+
+func TestTextToSpeechInputStreamKeepAlive(t *testing.T) {
+	textsCh := make(chan []string, 1)
+	server := testWSServer(t, func(conn *websocket.Conn, n int) {
+		textsCh <- serveInputStream(t, conn, "audio")
+	})
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout).With(elevenlabs.WithStreamKeepAlive(20 * time.Millisecond))
+	text := make(chan string)
+	go func() {
+		text <- "Hello "
+		time.Sleep(100 * time.Millisecond)
+		close(text)
+	}()
+	err := client.TextToSpeechInputStream(text, nil, &bytes.Buffer{}, "voiceID", elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	gotTexts := <-textsCh
+	if len(gotTexts) < 4 || gotTexts[1] != "Hello " || gotTexts[2] != " " {
+		t.Errorf("Expected keep-alive messages to be sent after the text, got texts %q", gotTexts)
+	}
+}
+
+func TestAudioDuration(t *testing.T) {
+	testCases := []struct {
+		name        string
+		size        int
+		format      string
+		expDuration time.Duration
+		expError    bool
+	}{
+		{name: "pcm 16kHz", size: 32000, format: "pcm_16000", expDuration: time.Second},
+		{name: "pcm 44.1kHz", size: 44100, format: "pcm_44100", expDuration: 500 * time.Millisecond},
+		{name: "ulaw 8kHz", size: 4000, format: "ulaw_8000", expDuration: 500 * time.Millisecond},
+		{name: "mp3", size: 1000, format: "mp3_44100_128", expError: true},
+		{name: "invalid format", size: 1000, format: "pcm", expError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := elevenlabs.AudioDuration(make([]byte, tc.size), tc.format)
+			if tc.expError {
+				if err == nil {
+					t.Errorf("Expected an error, got duration %s", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if d != tc.expDuration {
+				t.Errorf("Expected duration %s, got %s", tc.expDuration, d)
+			}
+		})
+	}
+}
+
+func TestPCMToWAV(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4, 5, 6}
+	wav := elevenlabs.PCMToWAV(pcm, 24000)
+	if len(wav) != 44+len(pcm) {
+		t.Fatalf("Expected %d bytes, got %d", 44+len(pcm), len(wav))
+	}
+	if string(wav[0:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Errorf("Unexpected WAV header %q", wav[:44])
+	}
+	if got := binary.LittleEndian.Uint32(wav[24:]); got != 24000 {
+		t.Errorf("Expected sample rate 24000, got %d", got)
+	}
+	if got := binary.LittleEndian.Uint32(wav[40:]); got != uint32(len(pcm)) {
+		t.Errorf("Expected data size %d, got %d", len(pcm), got)
+	}
+	if !bytes.Equal(wav[44:], pcm) {
+		t.Errorf("Expected data %v, got %v", pcm, wav[44:])
+	}
+}
+
+// recordingWriter records the data of every write.
+type recordingWriter struct {
+	writes [][]byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// mp3Frame returns an MP3 frame of the given length with the given 4-byte header.
+func mp3Frame(header []byte, length int) []byte {
+	return append(append([]byte(nil), header...), bytes.Repeat([]byte{0x55}, length-len(header))...)
+}
+
+func TestFrameWriter(t *testing.T) {
+	// MPEG-1 layer III, 128 kbit/s, 44100 Hz: 417 bytes, 418 with padding.
+	mpeg1 := []byte{0xff, 0xfb, 0x90, 0x64}
+	mpeg1Padded := []byte{0xff, 0xfb, 0x92, 0x64}
+	// MPEG-2 layer III, 32 kbit/s, 22050 Hz: 104 bytes.
+	mpeg2 := []byte{0xff, 0xf3, 0x40, 0xc4}
+	// ID3v2.4 tag with 20 bytes of content.
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20}, make([]byte, 20)...)
+
+	testCases := []struct {
+		name      string
+		format    string
+		units     [][]byte
+		chunkSize int
+	}{
+		{
+			name:      "mp3_44100_128",
+			format:    elevenlabs.FormatMP3_44100_128,
+			units:     [][]byte{mp3Frame(mpeg1, 417), mp3Frame(mpeg1Padded, 418), mp3Frame(mpeg1, 417)},
+			chunkSize: 100,
+		},
+		{
+			name:      "mp3 with ID3 tag",
+			format:    elevenlabs.FormatMP3_22050_32,
+			units:     [][]byte{id3, mp3Frame(mpeg2, 104), mp3Frame(mpeg2, 104), mp3Frame(mpeg2, 104)},
+			chunkSize: 33,
+		},
+		{
+			name:      "mp3 with junk between frames",
+			format:    elevenlabs.FormatMP3_44100_128,
+			units:     [][]byte{mp3Frame(mpeg1, 417), append([]byte{0, 1, 2}, mp3Frame(mpeg1, 417)...)},
+			chunkSize: 250,
+		},
+		{
+			name:      "pcm_16000",
+			format:    elevenlabs.FormatPCM_16000,
+			units:     [][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}},
+			chunkSize: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Join(tc.units, nil)
+			rec := &recordingWriter{}
+			fw := elevenlabs.NewFrameWriter(rec, tc.format)
+			for i := 0; i < len(data); i += tc.chunkSize {
+				end := i + tc.chunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if n, err := fw.Write(data[i:end]); err != nil || n != end-i {
+					t.Fatalf("Expected %d bytes to be written without errors, got %d and %v", end-i, n, err)
+				}
+			}
+			if err := fw.Flush(); err != nil {
+				t.Fatalf("Expected no errors from Flush, got %q", err)
+			}
+
+			if got := bytes.Join(rec.writes, nil); !bytes.Equal(data, got) {
+				t.Fatalf("Expected all data to be written unchanged, got %d of %d bytes", len(got), len(data))
+			}
+			// Every write must end on a boundary between units.
+			boundaries := map[int]bool{}
+			offset := 0
+			for _, u := range tc.units {
+				offset += len(u)
+				boundaries[offset] = true
+			}
+			offset = 0
+			for i, w := range rec.writes {
+				offset += len(w)
+				if !boundaries[offset] {
+					t.Errorf("Expected write %d to end on a frame boundary, ended at offset %d", i, offset)
+				}
+			}
+		})
+	}
+}
+
+func TestFrameWriterFlushIncomplete(t *testing.T) {
+	rec := &recordingWriter{}
+	fw := elevenlabs.NewFrameWriter(rec, elevenlabs.FormatMP3_44100_128)
+	frame := mp3Frame([]byte{0xff, 0xfb, 0x90, 0x64}, 417)
+	fw.Write(frame[:200])
+	if len(rec.writes) != 0 {
+		t.Errorf("Expected an incomplete frame to be buffered, got %d writes", len(rec.writes))
+	}
+	if err := fw.Flush(); err != nil {
+		t.Fatalf("Expected no errors from Flush, got %q", err)
+	}
+	if len(rec.writes) != 1 || !bytes.Equal(frame[:200], rec.writes[0]) {
+		t.Errorf("Expected Flush to write the incomplete frame, got %d writes", len(rec.writes))
+	}
+
+	rec = &recordingWriter{}
+	fw = elevenlabs.NewFrameWriter(rec, elevenlabs.FormatULaw_8000)
+	fw.Write([]byte{1, 2, 3})
+	if len(rec.writes) != 1 {
+		t.Errorf("Expected ulaw audio to be written without buffering, got %d writes", len(rec.writes))
+	}
+}
+
+func TestTextToSpeechWAV(t *testing.T) {
+	pcm := []byte("testpcmbytes")
+	server := testServer(t, testServerConfig{
+		expectedMethod:   http.MethodPost,
+		expectedQueryStr: "output_format=pcm_22050",
+		statusCode:       http.StatusOK,
+		responseBody:     pcm,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	audio, err := client.TextToSpeech("voiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}, elevenlabs.OutputFormat(elevenlabs.FormatWAV_22050))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if exp := elevenlabs.PCMToWAV(pcm, 22050); !bytes.Equal(exp, audio) {
+		t.Errorf("Expected WAV audio %q, got %q", exp, audio)
+	}
+}
+
+func TestAlignmentDuration(t *testing.T) {
+	segment := elevenlabs.StreamingAlignmentSegment{
+		CharStartTimesMs: []int{0, 100, 250},
+		CharDurationsMs:  []int{100, 150, 80},
+		Chars:            []string{"H", "i", "!"},
+	}
+	if d := segment.Duration(); d != 330*time.Millisecond {
+		t.Errorf("Expected duration %s, got %s", 330*time.Millisecond, d)
+	}
+	if d := (elevenlabs.StreamingAlignmentSegment{}).Duration(); d != 0 {
+		t.Errorf("Expected zero duration for an empty segment, got %s", d)
+	}
+}
+
+func TestGetModels(t *testing.T) {
+	respBody := testRespBodies["TestGetModels"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	models, err := client.GetModels()
+	if err != nil {
+		t.Errorf("Expected no errors from `GetModels`, got \"%T\" error: %q", err, err)
+	}
+	if len(models) != 1 {
+		t.Fatalf("Expected unmarshalled response to contain exactly one model, got %d", len(models))
+	}
+	var expModels []elevenlabs.Model
+	if err := json.Unmarshal(respBody, &expModels); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expModels, models) {
+		t.Errorf("Unexpected Model in response: %+v", models[0])
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	type condHeaders struct{ ifNoneMatch, ifModifiedSince string }
+	headersCh := make(chan condHeaders, 10)
+	const lastModified = "Wed, 01 May 2024 00:00:00 GMT"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		headersCh <- condHeaders{r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(testRespBodies["TestGetModels"])
+	})
+	mux.HandleFunc("/voices", func(w http.ResponseWriter, r *http.Request) {
+		headersCh <- condHeaders{r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")}
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write(testRespBodies["TestGetVoices"])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	conditional := client.With(elevenlabs.WithConditionalRequests())
+
+	firstModels, err := conditional.GetModels()
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := <-headersCh; got != (condHeaders{}) {
+		t.Errorf("Expected no conditional headers on the first request, got %+v", got)
+	}
+	models, err := conditional.GetModels()
+	if err != nil {
+		t.Fatalf("Expected no errors on 304, got error: %q", err)
+	}
+	if got := <-headersCh; got.ifNoneMatch != `"v1"` {
+		t.Errorf("Expected If-None-Match %q, got %q", `"v1"`, got.ifNoneMatch)
+	}
+	if len(models) == 0 || !reflect.DeepEqual(firstModels, models) {
+		t.Errorf("Expected the cached models %+v on 304, got %+v", firstModels, models)
+	}
+
+	firstVoices, err := conditional.GetVoices()
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	<-headersCh
+	voices, err := conditional.GetVoices()
+	if err != nil {
+		t.Fatalf("Expected no errors on 304, got error: %q", err)
+	}
+	if got := <-headersCh; got.ifModifiedSince != lastModified {
+		t.Errorf("Expected If-Modified-Since %q, got %q", lastModified, got.ifModifiedSince)
+	}
+	if len(voices) == 0 || !reflect.DeepEqual(firstVoices, voices) {
+		t.Errorf("Expected the cached voices %+v on 304, got %+v", firstVoices, voices)
+	}
+
+	if _, err := client.GetModels(); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := <-headersCh; got != (condHeaders{}) {
+		t.Errorf("Expected no conditional headers without WithConditionalRequests, got %+v", got)
+	}
+}
+
+func TestUnsolicitedNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	for name, c := range map[string]*elevenlabs.Client{
+		"plain":       client,
+		"conditional": client.With(elevenlabs.WithConditionalRequests()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.GetModels()
+			var statusErr *elevenlabs.UnexpectedStatusError
+			if !errors.As(err, &statusErr) || statusErr.HTTPStatus != http.StatusNotModified {
+				t.Errorf("Expected an UnexpectedStatusError with status 304 without validators sent, got %v", err)
+			}
+		})
+	}
+}
+
+func TestModelMaxChars(t *testing.T) {
+	var models []elevenlabs.Model
+	body := []byte(`[
+		{"model_id":"eleven_multilingual_v2","max_characters_request_free_user":2500,"max_characters_request_subscribed_user":5000,"maximum_text_length_per_request":10000,"concurrency_group":"standard"},
+		{"model_id":"eleven_flash_v2_5","maximum_text_length_per_request":40000,"concurrency_group":"turbo"}
+	]`)
+	if err := json.Unmarshal(body, &models); err != nil {
+		t.Fatalf("Failed to unmarshal models: %s", err)
+	}
+	if models[0].MaximumTextLengthPerRequest != 10000 || models[0].ConcurrencyGroup != "standard" {
+		t.Errorf("Unexpected model %+v", models[0])
+	}
+	testCases := []struct {
+		model      elevenlabs.Model
+		subscribed bool
+		exp        int
+	}{
+		{model: models[0], subscribed: false, exp: 2500},
+		{model: models[0], subscribed: true, exp: 5000},
+		{model: models[1], subscribed: true, exp: 40000},
+		{model: elevenlabs.Model{}, subscribed: true, exp: 0},
+	}
+	for _, tc := range testCases {
+		if got := tc.model.MaxChars(tc.subscribed); got != tc.exp {
+			t.Errorf("Expected MaxChars(%t) of %q to be %d, got %d", tc.subscribed, tc.model.ModelId, tc.exp, got)
+		}
+	}
+}
+
+func TestVoiceLabels(t *testing.T) {
+	var voice elevenlabs.Voice
+	body := []byte(`{"voice_id":"TestVoiceID","labels":{"accent":"american","gender":"female","age":"young","use_case":"narration","description":"calm"}}`)
+	if err := json.Unmarshal(body, &voice); err != nil {
+		t.Fatalf("Failed to unmarshal voice: %s", err)
+	}
+	got := []string{voice.Accent(), voice.Gender(), voice.Age(), voice.UseCase()}
+	if exp := []string{"american", "female", "young", "narration"}; !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected labels %q, got %q", exp, got)
+	}
+
+	var unlabeled elevenlabs.Voice
+	got = []string{unlabeled.Accent(), unlabeled.Gender(), unlabeled.Age(), unlabeled.UseCase()}
+	if exp := []string{"", "", "", ""}; !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected empty labels for a voice without labels, got %q", got)
+	}
+}
+
+func TestModelCanFineTune(t *testing.T) {
+	var models []elevenlabs.Model
+	body := []byte(`[
+		{"model_id":"eleven_multilingual_v2","name":"Eleven Multilingual v2","description":"Our most lifelike model","can_be_finetuned":true,"requires_alpha_access":false,"can_do_text_to_speech":true},
+		{"model_id":"eleven_alpha","name":"Eleven Alpha","description":"Experimental model","can_be_finetuned":false,"requires_alpha_access":true,"can_do_text_to_speech":true}
+	]`)
+	if err := json.Unmarshal(body, &models); err != nil {
+		t.Fatalf("Failed to unmarshal models: %s", err)
+	}
+	if m := models[0]; m.Description != "Our most lifelike model" || m.RequiresAlphaAccess || !m.CanFineTune() {
+		t.Errorf("Unexpected model %+v", m)
+	}
+	if m := models[1]; m.Description != "Experimental model" || !m.RequiresAlphaAccess || m.CanFineTune() {
+		t.Errorf("Unexpected model %+v", m)
+	}
+}
+
+func TestModelSupportsLanguage(t *testing.T) {
+	model := elevenlabs.Model{Languages: []elevenlabs.Language{{LanguageId: "en", Name: "English"}, {LanguageId: "ja", Name: "Japanese"}}}
+	for code, exp := range map[string]bool{"en": true, "JA": true, "fr": false, "": false} {
+		if got := model.SupportsLanguage(code); got != exp {
+			t.Errorf("Expected SupportsLanguage(%q) to return %t, got %t", code, exp, got)
+		}
+	}
+}
+
+func TestValidateLanguageForModel(t *testing.T) {
+	var modelRequests, ttsRequests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		modelRequests++
+		w.Write(testRespBodies["TestGetModels"])
+	})
+	mux.HandleFunc("/text-to-speech/", func(w http.ResponseWriter, r *http.Request) {
+		ttsRequests++
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	if err := client.ValidateLanguageForModel("TestModelID", "LangIDEnglish"); err != nil {
+		t.Errorf("Expected supported language to validate, got error: %q", err)
+	}
+	if err := client.ValidateLanguageForModel("TestModelID", "fr"); err == nil {
+		t.Error("Expected an error for an unsupported language, got nil")
+	}
+	if err := client.ValidateLanguageForModel("UnknownModelID", "LangIDEnglish"); err == nil {
+		t.Error("Expected an error for an unknown model, got nil")
+	}
+	if modelRequests != 1 {
+		t.Errorf("Expected models to be retrieved once, got %d requests", modelRequests)
+	}
+
+	ttsReq := elevenlabs.TextToSpeechRequest{Text: "Bonjour", ModelID: "TestModelID", LanguageCode: "fr"}
+	if _, err := client.With(elevenlabs.WithLanguageValidation()).TextToSpeech("voiceID", ttsReq); err == nil {
+		t.Error("Expected TextToSpeech with language validation to return an error, got nil")
+	}
+	if _, err := client.With(elevenlabs.WithLanguageValidation()).BuildTextToSpeechRequest("voiceID", ttsReq); err == nil {
+		t.Error("Expected BuildTextToSpeechRequest with language validation to return an error, got nil")
+	}
+	if _, err := client.With(elevenlabs.WithLanguageValidation()).BuildTextToSpeechStreamRequest("voiceID", ttsReq); err == nil {
+		t.Error("Expected BuildTextToSpeechStreamRequest with language validation to return an error, got nil")
+	}
+	if ttsRequests != 0 {
+		t.Errorf("Expected no text-to-speech request to be sent, got %d", ttsRequests)
+	}
+	if _, err := client.TextToSpeech("voiceID", ttsReq); err != nil {
+		t.Errorf("Expected TextToSpeech without language validation to succeed, got error: %q", err)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	testCases := []struct {
+		name    string
+		text    string
+		expText string
+		expTags []string
+	}{
+		{
+			name:    "plain text",
+			text:    "Hello, 1 < 2 and 3 > 2.",
+			expText: "Hello, 1 < 2 and 3 > 2.",
+		},
+		{
+			name:    "supported tags",
+			text:    `Wait. <break time="1.5s" /> Say <phoneme alphabet="ipa" ph="təˈmeɪtoʊ">tomato</phoneme>.`,
+			expText: `Wait. <break time="1.5s" /> Say <phoneme alphabet="ipa" ph="təˈmeɪtoʊ">tomato</phoneme>.`,
+		},
+		{
+			name:    "unsupported tags",
+			text:    `<speak>I <emphasis level="strong">really</emphasis> mean it.<BREAK time="1s"/></speak>`,
+			expText: `I really mean it.<BREAK time="1s"/>`,
+			expTags: []string{"<speak>", `<emphasis level="strong">`, "</emphasis>", "</speak>"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := elevenlabs.SanitizeText(tc.text); got != tc.expText {
+				t.Errorf("Expected sanitized text %q, got %q", tc.expText, got)
+			}
+			err := elevenlabs.ValidateText(tc.text)
+			if tc.expTags == nil {
+				if err != nil {
+					t.Errorf("Expected no errors, got error: %q", err)
+				}
+				return
+			}
+			var tagErr *elevenlabs.UnsupportedTagError
+			if !errors.As(err, &tagErr) {
+				t.Fatalf("Expected an *UnsupportedTagError, got %v", err)
+			}
+			if !reflect.DeepEqual(tc.expTags, tagErr.Tags) {
+				t.Errorf("Expected unsupported tags %q, got %q", tc.expTags, tagErr.Tags)
+			}
+		})
+	}
+}
+
+func TestBreak(t *testing.T) {
+	testCases := map[time.Duration]string{
+		time.Second:             `<break time="1s" />`,
+		1500 * time.Millisecond: `<break time="1.5s" />`,
+		250 * time.Millisecond:  `<break time="0.25s" />`,
+		-time.Second:            `<break time="0s" />`,
+	}
+	for d, exp := range testCases {
+		if got := elevenlabs.Break(d); got != exp {
+			t.Errorf("Expected Break(%s) to return %q, got %q", d, exp, got)
+		}
+	}
+}
+
+func TestTextBuilder(t *testing.T) {
+	var empty elevenlabs.TextBuilder
+	if got := empty.String(); got != "" {
+		t.Errorf("Expected an empty builder to return an empty string, got %q", got)
+	}
+
+	text := new(elevenlabs.TextBuilder).
+		Text("Chapter one.").
+		Pause(2 * time.Second).
+		Text("It was a dark night. ").
+		Pause(500 * time.Millisecond).
+		Text("").
+		Text("The end.").
+		String()
+	exp := `Chapter one. <break time="2s" /> It was a dark night. <break time="0.5s" /> The end.`
+	if text != exp {
+		t.Errorf("Expected text %q, got %q", exp, text)
+	}
+	if got := elevenlabs.SanitizeText(text); got != text {
+		t.Errorf("Expected the text to be left unchanged by SanitizeText, got %q", got)
+	}
+}
+
+func TestWithTextSanitizing(t *testing.T) {
+	textCh := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req elevenlabs.TextToSpeechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		textCh <- req.Text
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{Text: `<speak>Hello <break time="1s" /> world</speak>`}
+
+	if _, err := client.With(elevenlabs.WithTextSanitizing(elevenlabs.SanitizeStrip)).TextToSpeech("TestVoiceID", ttsReq); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if exp, got := `Hello <break time="1s" /> world`, <-textCh; exp != got {
+		t.Errorf("Expected text %q to be sent, got %q", exp, got)
+	}
+
+	err := client.With(elevenlabs.WithTextSanitizing(elevenlabs.SanitizeError)).TextToSpeechStream(&bytes.Buffer{}, "TestVoiceID", ttsReq)
+	var tagErr *elevenlabs.UnsupportedTagError
+	if !errors.As(err, &tagErr) {
+		t.Errorf("Expected an *UnsupportedTagError, got %v", err)
+	}
+
+	if _, err := client.TextToSpeech("TestVoiceID", ttsReq); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if got := <-textCh; got != ttsReq.Text {
+		t.Errorf("Expected text %q to be sent unchanged by default, got %q", ttsReq.Text, got)
+	}
+
+	req, err := client.With(elevenlabs.WithTextSanitizing(elevenlabs.SanitizeStrip)).BuildTextToSpeechRequest("TestVoiceID", ttsReq)
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	var built elevenlabs.TextToSpeechRequest
+	if err := json.NewDecoder(req.Body).Decode(&built); err != nil {
+		t.Fatalf("Failed to decode request body: %s", err)
+	}
+	if exp := `Hello <break time="1s" /> world`; built.Text != exp {
+		t.Errorf("Expected text %q in the built request, got %q", exp, built.Text)
+	}
+	_, err = client.With(elevenlabs.WithTextSanitizing(elevenlabs.SanitizeError)).BuildTextToSpeechStreamRequest("TestVoiceID", ttsReq)
+	if !errors.As(err, &tagErr) {
+		t.Errorf("Expected an *UnsupportedTagError from the stream request builder, got %v", err)
+	}
+}
+
+func TestWillExceedLimit(t *testing.T) {
+	var modelRequests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/models", func(w http.ResponseWriter, r *http.Request) {
+		modelRequests++
+		w.Write([]byte(`[{"model_id":"limited","max_characters_request_free_user":2,"max_characters_request_subscribed_user":5},{"model_id":"unlimited"}]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	testCases := []struct {
+		name    string
+		modelID string
+		text    string
+		expOver bool
+		expN    int
+	}{
+		{name: "ASCII within limit", modelID: "limited", text: "hello", expOver: false, expN: 0},
+		{name: "ASCII over limit", modelID: "limited", text: "hello!!", expOver: true, expN: 2},
+		{name: "CJK within limit", modelID: "limited", text: "こんにちは", expOver: false, expN: 0},
+		{name: "CJK over limit", modelID: "limited", text: "你好，世界！", expOver: true, expN: 1},
+		{name: "No limit reported", modelID: "unlimited", text: "こんにちは、世界", expOver: false, expN: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			over, n, err := client.WillExceedLimit(tc.modelID, tc.text)
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if over != tc.expOver || n != tc.expN {
+				t.Errorf("Expected (%t, %d), got (%t, %d)", tc.expOver, tc.expN, over, n)
+			}
+		})
+	}
+
+	if _, _, err := client.WillExceedLimit("UnknownModelID", "hello"); err == nil {
+		t.Error("Expected an error for an unknown model, got nil")
+	}
+	if modelRequests != 1 {
+		t.Errorf("Expected models to be retrieved once, got %d requests", modelRequests)
+	}
+}
+
+func TestGetVoices(t *testing.T) {
+	respBody := testRespBodies["TestGetVoices"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	voices, err := client.GetVoices()
+	if err != nil {
+		t.Errorf("Expected no errors from `GetVoices`, got \"%T\" error: %q", err, err)
+	}
+	if len(voices) != 1 {
+		t.Fatalf("Expected unmarshalled response to contain exactly one model, got %d", len(voices))
+	}
+	var voicesResp elevenlabs.GetVoicesResponse
+	if err := json.Unmarshal(respBody, &voicesResp); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(voicesResp.Voices, voices) {
+		t.Errorf("Unexpected Voice in response: %+v", voices[0])
+	}
+}
+
+func TestGetVoicesPaged(t *testing.T) {
+	queries := make(chan url.Values, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+		switch r.URL.Query().Get("next_page_token") {
+		case "":
+			w.Write([]byte(`{"voices":[{"voice_id":"voice1"},{"voice_id":"voice2"}],"has_more":true,"next_page_token":"token1"}`))
+		case "token1":
+			w.Write([]byte(`{"voices":[{"voice_id":"voice3"},{"voice_id":"voice4"}],"has_more":true,"next_page_token":"token2"}`))
+		default:
+			w.Write([]byte(`{"voices":[{"voice_id":"voice5"}],"has_more":false}`))
+		}
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	var ids []string
+	resp, next, err := client.GetVoicesPaged(elevenlabs.PageSize(2))
+	for {
+		if err != nil {
+			t.Fatalf("Expected no errors, got error: %q", err)
+		}
+		for _, v := range resp.Voices {
+			ids = append(ids, v.VoiceId)
+		}
+		if next == nil {
+			break
+		}
+		resp, next, err = next()
+	}
+	if exp := []string{"voice1", "voice2", "voice3", "voice4", "voice5"}; !reflect.DeepEqual(exp, ids) {
+		t.Errorf("Expected voices %q, got %q", exp, ids)
+	}
+	for i, expToken := range []string{"", "token1", "token2"} {
+		q := <-queries
+		if q.Get("page_size") != "2" || q.Get("next_page_token") != expToken {
+			t.Errorf("Expected request %d to have page_size 2 and next_page_token %q, got query %q", i, expToken, q.Encode())
+		}
+	}
+}
+
+func TestGetVoicesPagedUnpaginated(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        testRespBodies["TestGetVoices"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	resp, next, err := client.GetVoicesPaged()
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	var expResp elevenlabs.GetVoicesResponse
+	if err := json.Unmarshal(testRespBodies["TestGetVoices"], &expResp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expResp, resp) {
+		t.Errorf("Expected all voices to be returned, got %+v", resp)
+	}
+	if next != nil {
+		t.Error("Expected a nil next page function, but it wasn't")
+	}
+}
+
+func TestGetVoicesCategoryQuery(t *testing.T) {
+	respBody := []byte(`{"voices":[{"voice_id":"id2","name":"Cloned","category":"cloned"}]}`)
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		expectedQueryStr:    "category=cloned",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	voices, err := client.GetVoices(elevenlabs.VoiceCategory("cloned"))
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetVoices`, got \"%T\" error: %q", err, err)
+	}
+	if len(voices) != 1 || voices[0].VoiceId != "id2" || voices[0].Category != "cloned" {
+		t.Errorf("Expected the filtered voice, got %+v", voices)
+	}
+}
+
+func TestGetVoicesByCategory(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		expectedQueryStr:    "category=premade",
+		statusCode:          http.StatusOK,
+		responseBody:        testRespBodies["TestGetVoices-Multiple"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	voices, err := client.GetVoicesByCategory("Premade")
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetVoicesByCategory`, got \"%T\" error: %q", err, err)
+	}
+	var ids []string
+	for _, v := range voices {
+		ids = append(ids, v.VoiceId)
+	}
+	if expIds := []string{"id1", "id3"}; !reflect.DeepEqual(expIds, ids) {
+		t.Errorf("Expected voices %q, got %q", expIds, ids)
+	}
+}
+
+func TestFindVoiceByName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		search   string
+		expFound bool
+		expId    string
+	}{
+		{name: "exact name", search: "Adam", expFound: true, expId: "id3"},
+		{name: "different case", search: "my clone", expFound: true, expId: "id2"},
+		{name: "unknown name", search: "Nobody", expFound: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testServer(t, testServerConfig{
+				expectedMethod: http.MethodGet,
+				statusCode:     http.StatusOK,
+				responseBody:   testRespBodies["TestGetVoices-Multiple"],
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			voice, found, err := client.FindVoiceByName(tc.search)
+			if err != nil {
+				t.Fatalf("Expected no errors from `FindVoiceByName`, got \"%T\" error: %q", err, err)
+			}
+			if found != tc.expFound || voice.VoiceId != tc.expId {
+				t.Errorf("Expected found=%t with voice ID %q, got found=%t with voice ID %q", tc.expFound, tc.expId, found, voice.VoiceId)
+			}
+		})
+	}
+}
+
+func TestGetDefaultVoiceSettings(t *testing.T) {
+	respBody := testRespBodies["TestGetDefaultVoiceSettings"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	vSettings, err := client.GetDefaultVoiceSettings()
+	if err != nil {
+		t.Errorf("Expected no errors from `GetDefaultVoiceSettings`, got \"%T\" error: %q", err, err)
+	}
+	var expSettings elevenlabs.VoiceSettings
+	if err := json.Unmarshal(respBody, &expSettings); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expSettings, vSettings) {
+		t.Errorf("Unexpected VoiceSettings in response: %+v", vSettings)
+	}
+}
+
+func TestDefaultVoiceSettingsCache(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		fmt.Fprintf(w, `{"stability":0.%d,"similarity_boost":0.75}`, n)
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	cached := client.With(elevenlabs.WithDefaultVoiceSettingsCache())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if settings, err := cached.GetDefaultVoiceSettings(); err != nil || *settings.Stability != 0.1 {
+				t.Errorf("Expected cached settings with stability 0.1, got %+v and error %v", settings, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if requests != 1 {
+		t.Errorf("Expected the settings to be retrieved once, got %d requests", requests)
+	}
+
+	if settings, err := cached.RefreshDefaultVoiceSettings(); err != nil || *settings.Stability != 0.2 {
+		t.Errorf("Expected refreshed settings with stability 0.2, got %+v and error %v", settings, err)
+	}
+	if settings, err := cached.GetDefaultVoiceSettings(); err != nil || *settings.Stability != 0.2 {
+		t.Errorf("Expected the refreshed settings to be cached, got %+v and error %v", settings, err)
+	}
+	if settings, err := client.GetDefaultVoiceSettings(); err != nil || *settings.Stability != 0.3 {
+		t.Errorf("Expected settings to be retrieved without caching by default, got %+v and error %v", settings, err)
+	}
+}
+
+func TestGetVoiceSettings(t *testing.T) {
+	respBody := testRespBodies["TestGetVoiceSettings"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	vSettings, err := client.GetVoiceSettings("TestVoiceID")
+	if err != nil {
+		t.Errorf("Expected no errors from `GetVoiceSettings`, got \"%T\" error: %q", err, err)
+	}
+	var expSettings elevenlabs.VoiceSettings
+	if err := json.Unmarshal(respBody, &expSettings); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expSettings, vSettings) {
+		t.Errorf("Unexpected VoiceSettings in response: %+v", vSettings)
+	}
+}
+
+func TestGetVoiceFineTuningStatus(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusOK,
+		responseBody:   testRespBodies["TestGetVoiceFineTuningStatus"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	status, err := client.GetVoiceFineTuningStatus("TestVoiceID")
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetVoiceFineTuningStatus`, got error: %q", err)
+	}
+	if !status.IsAllowedToFineTune {
+		t.Error("Expected the voice to be allowed to be fine-tuned")
+	}
+	expState := map[string]string{elevenlabs.ModelMultilingualV2: "fine_tuned", elevenlabs.ModelTurboV2_5: "fine_tuning"}
+	if !reflect.DeepEqual(expState, status.State) {
+		t.Errorf("Expected state %v, got %v", expState, status.State)
+	}
+	if p := status.Progress[elevenlabs.ModelTurboV2_5]; p != 0.42 {
+		t.Errorf("Expected progress 0.42, got %v", p)
+	}
+	if !reflect.DeepEqual([]string{"slice1", "slice2"}, status.SliceIds) {
+		t.Errorf("Expected slice IDs %q, got %q", []string{"slice1", "slice2"}, status.SliceIds)
+	}
+	if len(status.VerificationAttempts) != 1 || !status.VerificationAttempts[0].Accepted || status.VerificationAttempts[0].Recording.RecordingId != "TestRecordingID" {
+		t.Errorf("Unexpected verification attempts %+v", status.VerificationAttempts)
+	}
+}
+
+func TestGetSharedVoices(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:   http.MethodGet,
+		expectedQueryStr: "language=en&page=2&page_size=4",
+		statusCode:       http.StatusOK,
+		responseBody:     testRespBodies["TestGetSharedVoices"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	resp, err := client.GetSharedVoices(elevenlabs.PageSize(4), elevenlabs.Page(2), elevenlabs.VoiceLanguage("en"))
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetSharedVoices`, got error: %q", err)
+	}
+	if !resp.HasMore || len(resp.Voices) != 4 {
+		t.Fatalf("Expected 4 voices and more to come, got %+v", resp)
+	}
+	if v := resp.Voices[0]; v.PublicOwnerId != "owner1" || v.Name != "Aria" || v.ClonedByCount != 1200 || !v.FreeUsersAllowed {
+		t.Errorf("Unexpected first voice %+v", v)
+	}
+
+	byLanguage := elevenlabs.SharedVoicesByLanguage(resp.Voices)
+	names := func(voices []elevenlabs.SharedVoice) []string {
+		var n []string
+		for _, v := range voices {
+			n = append(n, v.Name)
+		}
+		return n
+	}
+	expByLanguage := map[string][]string{"en": {"Aria", "Roger"}, "de": {"Hans", "Greta"}}
+	if len(byLanguage) != len(expByLanguage) {
+		t.Errorf("Expected %d languages, got %d", len(expByLanguage), len(byLanguage))
+	}
+	for lang, exp := range expByLanguage {
+		if got := names(byLanguage[lang]); !reflect.DeepEqual(exp, got) {
+			t.Errorf("Expected voices %q for language %q, got %q", exp, lang, got)
+		}
+	}
+	byCategory := elevenlabs.SharedVoicesByCategory(byLanguage["de"])
+	if got := names(byCategory["professional"]); !reflect.DeepEqual([]string{"Greta"}, got) {
+		t.Errorf("Expected the professional German voices to be %q, got %q", []string{"Greta"}, got)
+	}
+}
+
+func TestGetVoiceSamples(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusOK,
+		responseBody:   testRespBodies["TestGetVoiceSamples"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	samples, err := client.GetVoiceSamples("TestVoiceID")
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetVoiceSamples`, got error: %q", err)
+	}
+	expSamples := []elevenlabs.VoiceSample{
+		{SampleId: "sample1", FileName: "intro.mp3", MimeType: "audio/mpeg", SizeBytes: 482133, Hash: "5d41402abc4b2a76b9719d911017c592"},
+		{SampleId: "sample2", FileName: "chapter 1.wav", MimeType: "audio/wav", SizeBytes: 1764044, Hash: "7d793037a0760186574b0282f2f435e7"},
+	}
+	if !reflect.DeepEqual(expSamples, samples) {
+		t.Errorf("Expected samples %+v, got %+v", expSamples, samples)
+	}
+}
+
+func TestGetVoice(t *testing.T) {
+	respBody := testRespBodies["TestGetVoice"]
+	testCases := []struct {
+		name           string
+		queries        []elevenlabs.QueryFunc
+		expQueryString string
+	}{
+		{
+			name: "No queries",
+		},
+		{
+			name:           "With settings query",
+			queries:        []elevenlabs.QueryFunc{elevenlabs.WithSettings()},
+			expQueryString: "with_settings=true",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testServer(t, testServerConfig{
+				expectedMethod:      http.MethodGet,
+				expectedContentType: contentTypeJSON,
+				expectedAccept:      "*/*",
+				statusCode:          http.StatusOK,
+				responseBody:        []byte(respBody),
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			voice, err := client.GetVoice("TestVoiceID", tc.queries...)
+			if err != nil {
+				t.Errorf("Expected no errors from `GetVoice`, got \"%T\" error: %q", err, err)
+			}
+			var expVoice elevenlabs.Voice
+			if err := json.Unmarshal([]byte(respBody), &expVoice); err != nil {
+				t.Fatalf("Failed to unmarshal test respBody: %s", err)
+			}
+			if !reflect.DeepEqual(expVoice, voice) {
+				t.Errorf("Unexpected Voice in response: %+v", voice)
+			}
+		})
+	}
+}
+
+func TestGetVoicePreview(t *testing.T) {
+	previewServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("xi-api-key"); key != "" {
+			t.Errorf("Preview server: expected no API key, got %q", key)
+		}
+		w.Write([]byte("testpreviewbytes"))
+	}))
+	defer previewServer.Close()
+
+	testCases := []struct {
+		name       string
+		previewUrl string
+		expError   error
+	}{
+		{name: "with preview", previewUrl: previewServer.URL + "/preview.mp3"},
+		{name: "without preview", expError: elevenlabs.ErrNoPreview},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			respBody, _ := json.Marshal(elevenlabs.Voice{VoiceId: "TestVoiceID", PreviewUrl: tc.previewUrl})
+			server := testServer(t, testServerConfig{
+				expectedMethod: http.MethodGet,
+				statusCode:     http.StatusOK,
+				responseBody:   respBody,
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			preview, err := client.GetVoicePreview("TestVoiceID")
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Expected error %v, got %v", tc.expError, err)
+			}
+			if tc.expError == nil && string(preview) != "testpreviewbytes" {
+				t.Errorf("Expected preview %q, got %q", "testpreviewbytes", preview)
+			}
+		})
+	}
+}
+
+func TestDeleteVoice(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodDelete,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	err := client.DeleteVoice("TestVoiceID")
+	if err != nil {
+		t.Errorf("Expected no errors from `DeleteVoice`, got \"%T\" error: %q", err, err)
+	}
+}
+
+func TestEditVoiceSettings(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodPost,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	err := client.EditVoiceSettings("TestVoiceID", elevenlabs.NewVoiceSettings(0.2, 0.7).WithStyle(0.3).WithSpeakerBoost(false))
+	if err != nil {
+		t.Errorf("Expected no errors, got error: %q", err)
+	}
+}
+
+func TestEditVoiceSettingsWithResult(t *testing.T) {
+	testCases := []struct {
+		name         string
+		responseBody string
+		expSettings  elevenlabs.VoiceSettings
+	}{
+		{
+			name:         "settings returned by the API",
+			responseBody: `{"stability":0.3,"similarity_boost":0.6,"style":0.1,"use_speaker_boost":true}`,
+			expSettings:  elevenlabs.NewVoiceSettings(0.3, 0.6).WithStyle(0.1).WithSpeakerBoost(true),
+		},
+		{
+			name:         "status only",
+			responseBody: `{"status":"ok"}`,
+			expSettings:  elevenlabs.NewVoiceSettings(0.2, 0.7).WithStyle(0),
+		},
+		{
+			name:        "empty body",
+			expSettings: elevenlabs.NewVoiceSettings(0.2, 0.7).WithStyle(0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/voices/TestVoiceID/settings/edit" {
+					t.Errorf("Server: expected path %q, got %q", "/voices/TestVoiceID/settings/edit", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", contentTypeJSON)
+				w.Write([]byte(tc.responseBody))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+			settings := elevenlabs.NewVoiceSettings(0.2, 0.7).WithStyle(0)
+			got, err := client.EditVoiceSettingsWithResult("TestVoiceID", settings)
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if !reflect.DeepEqual(tc.expSettings, got) {
+				t.Errorf("Expected settings %+v, got %+v", tc.expSettings, got)
+			}
+			if *settings.Stability != 0.2 {
+				t.Errorf("Expected the given settings to be left unchanged, got stability %v", *settings.Stability)
+			}
+		})
+	}
+}
+
+func TestPatchVoiceSettings(t *testing.T) {
+	editCh := make(chan map[string]any, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/voices/TestVoiceID/settings", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"stability":0.5,"similarity_boost":0.75,"style":0.3,"use_speaker_boost":true}`))
+	})
+	mux.HandleFunc("/voices/TestVoiceID/settings/edit", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		editCh <- body
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	err := client.PatchVoiceSettings("TestVoiceID", func(s *elevenlabs.VoiceSettings) {
+		s.Stability = elevenlabs.Float32(0.25)
+		s.SpeakerBoost = elevenlabs.Bool(false)
+	})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expBody := map[string]any{"stability": 0.25, "similarity_boost": 0.75, "style": 0.3, "use_speaker_boost": false}
+	if gotBody := <-editCh; !reflect.DeepEqual(expBody, gotBody) {
+		t.Errorf("Expected settings %v to be sent, got %v", expBody, gotBody)
+	}
+
+	if err := client.PatchVoiceSettings("UnknownVoiceID", func(s *elevenlabs.VoiceSettings) {}); err == nil {
+		t.Error("Expected an error for an unknown voice, got nil")
+	}
+}
+
+func TestVoiceSettingsRoundTrip(t *testing.T) {
+	settings := elevenlabs.NewVoiceSettings(0.4, 0.8).WithStyle(0.25).WithSpeakerBoost(true)
+	b, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatalf("Failed to marshal VoiceSettings: %s", err)
+	}
+	for _, key := range []string{`"stability":0.4`, `"similarity_boost":0.8`, `"style":0.25`, `"use_speaker_boost":true`} {
+		if !strings.Contains(string(b), key) {
+			t.Errorf("Expected marshalled VoiceSettings %s to contain %s", b, key)
+		}
+	}
+	var got elevenlabs.VoiceSettings
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Failed to unmarshal VoiceSettings: %s", err)
+	}
+	if !reflect.DeepEqual(settings, got) {
+		t.Errorf("Expected VoiceSettings %+v after round trip, got %+v", settings, got)
+	}
+}
+
+func TestVoiceSettingsZeroValues(t *testing.T) {
+	bodyCh := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Server: failed to decode request body: %s", err)
+		}
+		bodyCh <- body
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	err := client.EditVoiceSettings("TestVoiceID", elevenlabs.NewVoiceSettings(0, 0).WithStyle(0).WithSpeakerBoost(false))
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expBody := map[string]any{"stability": 0.0, "similarity_boost": 0.0, "style": 0.0, "use_speaker_boost": false}
+	if gotBody := <-bodyCh; !reflect.DeepEqual(expBody, gotBody) {
+		t.Errorf("Expected settings %v to be sent, got %v", expBody, gotBody)
+	}
+
+	b, err := json.Marshal(elevenlabs.VoiceSettings{})
+	if err != nil {
+		t.Fatalf("Failed to marshal VoiceSettings: %s", err)
+	}
+	if string(b) != "{}" {
+		t.Errorf("Expected unset settings to be omitted, got %s", b)
+	}
+}
+
+func TestAddVoice(t *testing.T) {
+	testCases := []struct {
+		name        string
+		paths       []string
+		expRespBody []byte
+		expError    bool
+	}{
+		{
+			name:        "with existing sample file",
+			paths:       []string{"testdata/fake.mp3"},
+			expRespBody: []byte(`{"voice_id":"TestVoiceId"}`),
+			expError:    false,
+		},
+		{
+			name:        "with non-existent sample file",
+			paths:       []string{"testdata/not-there.mp3"},
+			expRespBody: []byte("{}"),
+			expError:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := elevenlabs.AddEditVoiceRequest{
+				Name:        "NewTestVoiceName",
+				FilePaths:   tc.paths,
+				Description: "New voice description here",
+				Labels:      map[string]string{"accent": "australian", "foo": "bar"},
+			}
+			server := testServer(t, testServerConfig{
+				expectedMethod:      http.MethodPost,
+				expectedContentType: contentMultipart,
+				expectedAccept:      "*/*",
+				statusCode:          http.StatusOK,
+				responseBody:        tc.expRespBody,
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			id, err := client.AddVoice(request)
+			if err != nil {
+				if !tc.expError {
+					t.Errorf("Expected no errors, got error: %q", err)
+				}
+				return
+			}
+			if tc.expError {
+				t.Error("Expected an error, got nil")
+				return
+			}
+
+			if id != "TestVoiceId" {
+				t.Errorf("Expected AddVoice to return voice ID %q, got %q", "TestVoiceId", id)
+			}
+
+		})
+	}
+}
+
+func TestAddVoiceFull(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodPost,
+		expectedContentType: contentMultipart,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        []byte(`{"voice_id":"TestVoiceId","requires_verification":true}`),
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	request := elevenlabs.AddEditVoiceRequest{Name: "NewTestVoiceName", FilePaths: []string{"testdata/fake.mp3"}}
+	resp, err := client.AddVoiceFull(request)
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if exp := (elevenlabs.AddVoiceResponse{VoiceId: "TestVoiceId", RequiresVerification: true}); resp != exp {
+		t.Errorf("Expected response %+v, got %+v", exp, resp)
+	}
+}
+
+func TestAddVoiceMultipartFields(t *testing.T) {
+	var gotForm map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Server: failed to parse multipart form: %s", err)
+		}
+		gotForm = r.MultipartForm.Value
+		w.Write([]byte(`{"voice_id":"TestVoiceId"}`))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	_, err := client.AddVoice(elevenlabs.AddEditVoiceRequest{
+		Name:        "NewTestVoiceName",
+		FilePaths:   []string{"testdata/fake.mp3"},
+		Description: "New voice description here",
+		Labels:      map[string]string{"foo": "bar", "accent": "australian"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expForm := map[string][]string{
+		"name":                    {"NewTestVoiceName"},
+		"description":             {"New voice description here"},
+		"labels":                  {`{"accent":"australian","foo":"bar"}`},
+		"remove_background_noise": {"false"},
+	}
+	if !reflect.DeepEqual(expForm, gotForm) {
+		t.Errorf("Expected multipart form values %q, got %q", expForm, gotForm)
+	}
+}
+
+func TestAddVoiceSampleContentType(t *testing.T) {
+	filesCh := make(chan []*multipart.FileHeader, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Server: failed to parse multipart form: %s", err)
+		}
+		filesCh <- r.MultipartForm.File["files"]
+		w.Write([]byte(`{"voice_id":"TestVoiceId"}`))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	_, err := client.AddVoice(elevenlabs.AddEditVoiceRequest{
+		Name:      "TestVoice",
+		FilePaths: []string{"testdata/fake.mp3"},
+		Samples: []elevenlabs.SampleFile{
+			{Path: "testdata/fake.mp3", ContentType: "audio/mpeg"},
+			{Path: "testdata/fake.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	files := <-filesCh
+	expected := []struct{ filename, contentType string }{
+		{"fake.mp3", "application/octet-stream"},
+		{"fake.mp3", "audio/mpeg"},
+		{"fake.txt", "application/octet-stream"},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(files))
+	}
+	for i, exp := range expected {
+		if files[i].Filename != exp.filename {
+			t.Errorf("Expected file %d to be named %q, got %q", i, exp.filename, files[i].Filename)
+		}
+		if got := files[i].Header.Get("Content-Type"); got != exp.contentType {
+			t.Errorf("Expected file %d to have Content-Type %q, got %q", i, exp.contentType, got)
+		}
+	}
+}
+
+func TestAddVoiceRemoveBackgroundNoise(t *testing.T) {
+	for _, remove := range []bool{true, false} {
+		t.Run(fmt.Sprint(remove), func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.FormValue("remove_background_noise")
+				w.Write([]byte(`{"voice_id":"TestVoiceId"}`))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			_, err := client.AddVoice(elevenlabs.AddEditVoiceRequest{Name: "TestVoice", RemoveBackgroundNoise: remove})
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if exp := fmt.Sprint(remove); got != exp {
+				t.Errorf("Expected remove_background_noise field %q, got %q", exp, got)
+			}
+		})
+	}
+}
+
+func TestAddVoiceIdempotent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		voicesBody  string
+		expId       string
+		expRequests []string
+	}{
+		{
+			name:        "voice added despite the timeout",
+			voicesBody:  `{"voices":[{"voice_id":"AddedVoiceId","name":"NewTestVoiceName"}]}`,
+			expId:       "AddedVoiceId",
+			expRequests: []string{"POST /voices/add", "GET /voices"},
+		},
+		{
+			name:        "voice not added",
+			voicesBody:  `{"voices":[{"voice_id":"OtherVoiceId","name":"OtherVoice"}]}`,
+			expId:       "RetriedVoiceId",
+			expRequests: []string{"POST /voices/add", "GET /voices", "POST /voices/add"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := make(chan string, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests <- r.Method + " " + r.URL.Path
+				w.Header().Set("Content-Type", contentTypeJSON)
+				if r.Method == http.MethodGet {
+					w.Write([]byte(tc.voicesBody))
+					return
+				}
+				if len(requests) == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				w.Write([]byte(`{"voice_id":"RetriedVoiceId"}`))
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, 100*time.Millisecond)
+			id, err := client.AddVoiceIdempotent(elevenlabs.AddEditVoiceRequest{Name: "NewTestVoiceName"})
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if id != tc.expId {
+				t.Errorf("Expected voice ID %q, got %q", tc.expId, id)
+			}
+			server.Close()
+			close(requests)
+			var gotRequests []string
+			for r := range requests {
+				gotRequests = append(gotRequests, r)
+			}
+			if !reflect.DeepEqual(tc.expRequests, gotRequests) {
+				t.Errorf("Expected requests %q, got %q", tc.expRequests, gotRequests)
+			}
+		})
+	}
+}
+
+func TestEditVoice(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodPost,
+		expectedContentType: contentMultipart,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	err := client.EditVoice("TestVoiceID", elevenlabs.AddEditVoiceRequest{Name: "TestVoice"})
+	if err != nil {
+		t.Errorf("Expected no errors, got error: %q", err)
+	}
+}
+
+func TestEditVoiceMetadata(t *testing.T) {
+	testCases := []struct {
+		name        string
+		description string
+		labels      map[string]string
+		expForm     map[string][]string
+	}{
+		{
+			name:        "all fields",
+			description: "New description",
+			labels:      map[string]string{"accent": "british"},
+			expForm:     map[string][]string{"name": {"TestVoice"}, "description": {"New description"}, "labels": {`{"accent":"british"}`}},
+		},
+		{
+			name:    "name only",
+			expForm: map[string][]string{"name": {"TestVoice"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formCh := make(chan *multipart.Form, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/voices/TestVoiceID/edit" {
+					t.Errorf("Server: unexpected path %q", r.URL.Path)
+				}
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Errorf("Server: failed to parse multipart form: %s", err)
+				}
+				formCh <- r.MultipartForm
+			}))
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			if err := client.EditVoiceMetadata("TestVoiceID", "TestVoice", tc.description, tc.labels); err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			form := <-formCh
+			if !reflect.DeepEqual(tc.expForm, form.Value) {
+				t.Errorf("Expected multipart form values %q, got %q", tc.expForm, form.Value)
+			}
+			if len(form.File) != 0 {
+				t.Errorf("Expected no files to be uploaded, got %d", len(form.File))
+			}
+		})
+	}
+}
+
+func TestAddHistoryItemAsSample(t *testing.T) {
+	audio := []byte("history item audio")
+	formCh := make(chan *multipart.Form, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /voices/TestVoiceID":
+			w.Write([]byte(`{"voice_id":"TestVoiceID","name":"TestVoice"}`))
+		case "GET /history/TestHistoryItemID/audio":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write(audio)
+		case "POST /voices/TestVoiceID/edit":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("Server: failed to parse multipart form: %s", err)
+			}
+			formCh <- r.MultipartForm
+		default:
+			t.Errorf("Server: unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	if err := client.AddHistoryItemAsSample("TestVoiceID", "TestHistoryItemID"); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+
+	form := <-formCh
+	if exp := map[string][]string{"name": {"TestVoice"}}; !reflect.DeepEqual(exp, form.Value) {
+		t.Errorf("Expected multipart form values %q, got %q", exp, form.Value)
+	}
+	files := form.File["files"]
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file to be uploaded, got %d", len(files))
+	}
+	if files[0].Filename != "TestHistoryItemID.mp3" || files[0].Header.Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("Expected file %q of type %q, got %q of type %q", "TestHistoryItemID.mp3", "audio/mpeg", files[0].Filename, files[0].Header.Get("Content-Type"))
+	}
+	f, err := files[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got, _ := io.ReadAll(f); !bytes.Equal(audio, got) {
+		t.Errorf("Expected the history item audio to be uploaded, got %q", got)
+	}
+}
+
+func TestDeleteSample(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodDelete,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	err := client.DeleteSample("TestVoiceID", "TestSampleID")
+	if err != nil {
+		t.Errorf("Expected no errors from `DeleteSample`, got \"%T\" error: %q", err, err)
+	}
+}
+
+func TestGetSampleAudio(t *testing.T) {
+	expRespBody := testRespBodies["TestGetSampleAudio"]
+
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        []byte(expRespBody),
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	respBody, err := client.GetSampleAudio("TestVoiceID", "TestSampleID")
+	if err != nil {
+		t.Errorf("Expected no errors from `GetSampleAudio`, got \"%T\" error: %q", err, err)
+	}
+	if string(respBody) != string(expRespBody) {
+		t.Errorf("Expected response %q, got %q", string(expRespBody), string(respBody))
+	}
+}
+
+func TestDownloadVoiceSamples(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/voices/TestVoiceID":
+			w.Header().Set("Content-Type", contentTypeJSON)
+			w.Write([]byte(`{"voice_id":"TestVoiceID","samples":[{"sample_id":"s1","file_name":"sample.mp3"},{"sample_id":"s2","file_name":"sample.mp3"}]}`))
+		case "/voices/TestVoiceID/samples/s1/audio", "/voices/TestVoiceID/samples/s2/audio":
+			w.Write([]byte("audio-" + strings.Split(r.URL.Path, "/")[4]))
+		default:
+			t.Errorf("Server: unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	zipBytes, err := client.DownloadVoiceSamples("TestVoiceID")
+	if err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	if err != nil {
+		t.Fatalf("Failed to read zip file: %s", err)
+	}
+	got := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %s", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(content)
+	}
+	exp := map[string]string{"01_sample.mp3": "audio-s1", "02_sample.mp3": "audio-s2"}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected zip files %q, got %q", exp, got)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	testCases := []struct {
+		name           string
+		queries        []elevenlabs.QueryFunc
+		expQueryString string
+		expStatus      int
+		respBody       []byte
+		expNextFunc    bool
+	}{
+		{
+			name:           "setting relevant queries",
+			queries:        []elevenlabs.QueryFunc{elevenlabs.PageSize(50), elevenlabs.StartAfter("fake-history-id")},
+			expQueryString: "page_size=50&start_after_history_item_id=fake-history-id",
+			respBody:       []byte("{}"),
+		},
+		{
+			name:        "return nil for next page function when has_more is false",
+			respBody:    testRespBodies["TestGetHistory-NoMore"],
+			expNextFunc: false,
+		},
+		{
+			name:        "return next page function when has_more is true",
+			respBody:    testRespBodies["TestGetHistory-HasMore"],
+			expNextFunc: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mockResponse := testRespBodies["TestGetHistory"]
+			config := testServerConfig{
+				keyOptional:         false,
+				expectedMethod:      "GET",
+				expectedContentType: "application/json",
+				expectedAccept:      "*/*",
+				expectedQueryStr:    tc.expQueryString,
+				statusCode:          http.StatusOK,
+				responseBody:        tc.respBody,
+				responseDelay:       0,
+			}
+			server := testServer(t, config)
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+			resp, nextPageFunc, err := client.GetHistory(tc.queries...)
+			if err != nil {
+				t.Fatalf("Expected GetHistory to return no error, got %q", err)
+			}
+
+			expectedResp := elevenlabs.GetHistoryResponse{}
+			err = json.Unmarshal(tc.respBody, &expectedResp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(resp, expectedResp) {
+				t.Errorf("Expected GetHistory to return response %+v, got %+v", expectedResp, resp)
+			}
+
+			if !tc.expNextFunc {
+				if nextPageFunc != nil {
+					t.Error("Expected GetHistory to return a nil next page function, but it didn't")
+				}
+				return
+			}
+
+			nextResp, _, err := nextPageFunc(elevenlabs.PageSize(99))
+			if err != nil {
+				t.Fatalf("Expected GetHistory to return no error, got %q", err)
+			}
+
+			expectedNextResp := elevenlabs.GetHistoryResponse{}
+			err = json.Unmarshal(tc.respBody, &expectedNextResp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(nextResp, expectedNextResp) {
+				t.Errorf("Expected calling next function to return response %+v, got %+v", expectedNextResp, nextResp)
+			}
+		})
+	}
+}
+
+func TestGetHistoryResponsePages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		respBody []byte
+		page     int
+		expPages int
+	}{
+		{name: "last page", respBody: testRespBodies["TestGetHistory-NoMore"], page: 3, expPages: 3},
+		{name: "more pages", respBody: testRespBodies["TestGetHistory-HasMore"], page: 1, expPages: 2},
+		{name: "invalid page", respBody: testRespBodies["TestGetHistory-HasMore"], page: 0, expPages: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp elevenlabs.GetHistoryResponse
+			if err := json.Unmarshal(tc.respBody, &resp); err != nil {
+				t.Fatalf("Failed to unmarshal test respBody: %s", err)
+			}
+			if resp.NumItems() != len(resp.History) {
+				t.Errorf("Expected %d items, got %d", len(resp.History), resp.NumItems())
+			}
+			if got := resp.EstimatedPages(tc.page); got != tc.expPages {
+				t.Errorf("Expected %d estimated pages, got %d", tc.expPages, got)
+			}
+		})
+	}
+}
+
+func TestGetHistoryItem(t *testing.T) {
+	respBody := testRespBodies["TestGetHistoryItem"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        respBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	historyItem, err := client.GetHistoryItem("TestHistoryItemID")
+	if err != nil {
+		t.Errorf("Expected no errors from `GetHistoryItem`, got \"%T\" error: %q", err, err)
+	}
+	var expItem elevenlabs.HistoryItem
+	if err := json.Unmarshal(respBody, &expItem); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expItem, historyItem) {
+		t.Errorf("Unexpected HistoryItem in response: %+v", historyItem)
+	}
+}
+
+func TestGetHistoryItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/history/")
+		if strings.HasPrefix(id, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Respond out of order to make sure the input order is preserved.
+		if id == "item1" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprintf(w, `{"history_item_id":%q}`, id)
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	ids := []string{"item1", "item2", "missing1", "item3", "item4", "item5"}
+	items, err := client.GetHistoryItems(ids)
+	if !errors.Is(err, elevenlabs.ErrNotFound) || !strings.Contains(err.Error(), "missing1") {
+		t.Errorf("Expected an ErrNotFound error mentioning %q, got %v", "missing1", err)
+	}
+	if len(items) != len(ids) {
+		t.Fatalf("Expected %d items, got %d", len(ids), len(items))
+	}
+	for i, id := range ids {
+		exp := id
+		if strings.HasPrefix(id, "missing") {
+			exp = ""
+		}
+		if items[i].HistoryItemId != exp {
+			t.Errorf("Expected item %d to have ID %q, got %q", i, exp, items[i].HistoryItemId)
+		}
+	}
+
+	if _, err := client.GetHistoryItems([]string{"item1", "item2"}); err != nil {
+		t.Errorf("Expected no errors, got error: %q", err)
 	}
 }
 
-func TestGetModels(t *testing.T) {
-	respBody := testRespBodies["TestGetModels"]
+func TestDeleteHistoryItem(t *testing.T) {
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodGet,
+		expectedMethod:      http.MethodDelete,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        respBody,
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	models, err := client.GetModels()
+	err := client.DeleteHistoryItem("TestHistoryItemID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetModels`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `DeleteHistoryItem`, got \"%T\" error: %q", err, err)
 	}
-	if len(models) != 1 {
-		t.Fatalf("Expected unmarshalled response to contain exactly one model, got %d", len(models))
+}
+
+func TestDeleteHistoryItems(t *testing.T) {
+	deleted := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Server: expected HTTP Method to be %q, got %q", http.MethodDelete, r.Method)
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/history/")
+		if strings.HasPrefix(id, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		deleted <- id
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	ids := []string{"item1", "missing1", "item2", "item3", "missing2", "item4"}
+	err := client.DeleteHistoryItems(ids)
+	if err == nil {
+		t.Fatal("Expected an error for the missing items, got nil")
 	}
-	var expModels []elevenlabs.Model
-	if err := json.Unmarshal(respBody, &expModels); err != nil {
-		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	if !errors.Is(err, elevenlabs.ErrNotFound) {
+		t.Errorf("Expected the error to match ErrNotFound, got %v", err)
 	}
-	if !reflect.DeepEqual(expModels, models) {
-		t.Errorf("Unexpected Model in response: %+v", models[0])
+	var statusErr *elevenlabs.UnexpectedStatusError
+	if !errors.As(err, &statusErr) || statusErr.HTTPStatus != http.StatusNotFound {
+		t.Errorf("Expected the error to hold an UnexpectedStatusError with status 404, got %v", err)
+	}
+	for _, id := range []string{"missing1", "missing2"} {
+		if !strings.Contains(err.Error(), id) {
+			t.Errorf("Expected the error to mention %q, got %q", id, err)
+		}
+	}
+	if err := client.DeleteHistoryItems([]string{"item5"}); err != nil {
+		t.Errorf("Expected no errors, got error: %q", err)
+	}
+
+	close(deleted)
+	got := map[string]bool{}
+	for id := range deleted {
+		got[id] = true
+	}
+	if exp := map[string]bool{"item1": true, "item2": true, "item3": true, "item4": true, "item5": true}; !reflect.DeepEqual(exp, got) {
+		t.Errorf("Expected items %v to be deleted, got %v", exp, got)
 	}
 }
 
-func TestGetVoices(t *testing.T) {
-	respBody := testRespBodies["TestGetVoices"]
+func TestDeleteHistoryItemsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := elevenlabs.NewMockClient(ctx, "http://127.0.0.1:0", mockAPIKey, mockTimeout)
+	if err := client.DeleteHistoryItems([]string{"item1", "item2"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetHistoryItemAudio(t *testing.T) {
+	expRespBody := testRespBodies["TestGetHistoryItemAudio"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        respBody,
+		responseBody:        []byte(expRespBody),
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	voices, err := client.GetVoices()
+	respBody, err := client.GetHistoryItemAudio("TestHistoryItemID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetVoices`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `GetHistoryItemAudio`, got \"%T\" error: %q", err, err)
 	}
-	if len(voices) != 1 {
-		t.Fatalf("Expected unmarshalled response to contain exactly one model, got %d", len(voices))
+	if string(respBody) != string(expRespBody) {
+		t.Errorf("Expected response %q, got %q", string(expRespBody), string(respBody))
 	}
-	var voicesResp elevenlabs.GetVoicesResponse
-	if err := json.Unmarshal(respBody, &voicesResp); err != nil {
-		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+}
+
+func TestStreamHistoryItemAudio(t *testing.T) {
+	expRespBody := testRespBodies["TestGetHistoryItemAudio"]
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
+		statusCode:          http.StatusOK,
+		responseBody:        expRespBody,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	w := bytes.Buffer{}
+	if err := client.StreamHistoryItemAudio(&w, "TestHistoryItemID"); err != nil {
+		t.Errorf("Expected no errors from `StreamHistoryItemAudio`, got \"%T\" error: %q", err, err)
 	}
-	if !reflect.DeepEqual(voicesResp.Voices, voices) {
-		t.Errorf("Unexpected Voice in response: %+v", voices[0])
+	if w.String() != string(expRespBody) {
+		t.Errorf("Expected response %q, got %q", string(expRespBody), w.String())
 	}
 }
 
-func TestGetDefaultVoiceSettings(t *testing.T) {
-	respBody := testRespBodies["TestGetDefaultVoiceSettings"]
+func TestHistoryItemAudioReader(t *testing.T) {
+	expRespBody := testRespBodies["TestGetHistoryItemAudio"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        respBody,
+		responseBody:        expRespBody,
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	vSettings, err := client.GetDefaultVoiceSettings()
+	r, err := client.HistoryItemAudioReader("TestHistoryItemID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetDefaultVoiceSettings`, got \"%T\" error: %q", err, err)
+		t.Fatalf("Expected no errors from `HistoryItemAudioReader`, got \"%T\" error: %q", err, err)
 	}
-	var expSettings elevenlabs.VoiceSettings
-	if err := json.Unmarshal(respBody, &expSettings); err != nil {
-		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Expected no errors reading the audio, got %q", err)
 	}
-	if !reflect.DeepEqual(expSettings, vSettings) {
-		t.Errorf("Unexpected VoiceSettings in response: %+v", vSettings)
+	if string(b) != string(expRespBody) {
+		t.Errorf("Expected response %q, got %q", string(expRespBody), string(b))
 	}
 }
 
-func TestGetVoiceSettings(t *testing.T) {
-	respBody := testRespBodies["TestGetVoiceSettings"]
+func TestHistoryItemAudioReaderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	r, err := client.HistoryItemAudioReader("missing")
+	if !errors.Is(err, elevenlabs.ErrNotFound) {
+		t.Errorf("Expected an ErrNotFound error, got %v", err)
+	}
+	if r != nil {
+		t.Errorf("Expected no reader on error, got %v", r)
+	}
+}
+
+func TestHistoryItemAudioReaderClose(t *testing.T) {
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(released)
+		chunk := bytes.Repeat([]byte{0xff}, 1024)
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	r, err := client.HistoryItemAudioReader("TestHistoryItemID")
+	if err != nil {
+		t.Fatalf("Expected no errors from `HistoryItemAudioReader`, got \"%T\" error: %q", err, err)
+	}
+	if _, err := io.ReadFull(r, make([]byte, 2048)); err != nil {
+		t.Fatalf("Expected no errors reading the audio, got %q", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Expected no errors closing the reader, got %q", err)
+	}
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Error("Expected the connection to be released after closing the reader")
+	}
+}
+
+func TestDownloadHistoryAudio(t *testing.T) {
+	expResponseBody := testRespBodies["TestDownloadHistoryAudio"]
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodGet,
+		expectedMethod:      http.MethodPost,
 		expectedContentType: contentTypeJSON,
-		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        respBody,
+		responseBody:        expResponseBody,
 	})
 	defer server.Close()
+
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	vSettings, err := client.GetVoiceSettings("TestVoiceID")
+	respBody, err := client.DownloadHistoryAudio(elevenlabs.DownloadHistoryRequest{HistoryItemIds: []string{"TestHistoryItemID"}})
+
 	if err != nil {
-		t.Errorf("Expected no errors from `GetVoiceSettings`, got \"%T\" error: %q", err, err)
-	}
-	var expSettings elevenlabs.VoiceSettings
-	if err := json.Unmarshal(respBody, &expSettings); err != nil {
-		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+		t.Errorf("Expected no errors, got error: %q", err)
 	}
-	if !reflect.DeepEqual(expSettings, vSettings) {
-		t.Errorf("Unexpected VoiceSettings in response: %+v", vSettings)
+
+	if string(respBody) != string(expResponseBody) {
+		t.Errorf("Expected response %q, got %q", string(expResponseBody), string(respBody))
 	}
 }
 
-func TestGetVoice(t *testing.T) {
-	respBody := testRespBodies["TestGetVoice"]
+func TestDownloadHistoryRequest(t *testing.T) {
 	testCases := []struct {
-		name           string
-		queries        []elevenlabs.QueryFunc
-		expQueryString string
+		name    string
+		request elevenlabs.DownloadHistoryRequest
+		expBody string
+		expZip  bool
 	}{
 		{
-			name: "No queries",
+			name:    "single item with default format",
+			request: elevenlabs.DownloadHistoryRequest{HistoryItemIds: []string{"id1"}},
+			expBody: `{"history_item_ids":["id1"]}`,
+			expZip:  false,
 		},
 		{
-			name:           "With settings query",
-			queries:        []elevenlabs.QueryFunc{elevenlabs.WithSettings()},
-			expQueryString: "with_settings=true",
+			name:    "multiple items as wav",
+			request: elevenlabs.DownloadHistoryRequest{HistoryItemIds: []string{"id1", "id2"}, OutputFormat: "wav"},
+			expBody: `{"history_item_ids":["id1","id2"],"output_format":"wav"}`,
+			expZip:  true,
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			server := testServer(t, testServerConfig{
-				expectedMethod:      http.MethodGet,
-				expectedContentType: contentTypeJSON,
-				expectedAccept:      "*/*",
-				statusCode:          http.StatusOK,
-				responseBody:        []byte(respBody),
-			})
-			defer server.Close()
-			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-			voice, err := client.GetVoice("TestVoiceID", tc.queries...)
+			b, err := json.Marshal(tc.request)
 			if err != nil {
-				t.Errorf("Expected no errors from `GetVoice`, got \"%T\" error: %q", err, err)
+				t.Fatal(err)
 			}
-			var expVoice elevenlabs.Voice
-			if err := json.Unmarshal([]byte(respBody), &expVoice); err != nil {
-				t.Fatalf("Failed to unmarshal test respBody: %s", err)
+			if string(b) != tc.expBody {
+				t.Errorf("Expected request body %s, got %s", tc.expBody, b)
 			}
-			if !reflect.DeepEqual(expVoice, voice) {
-				t.Errorf("Unexpected Voice in response: %+v", voice)
+			if tc.request.IsZip() != tc.expZip {
+				t.Errorf("Expected IsZip to return %t, got %t", tc.expZip, tc.request.IsZip())
 			}
 		})
 	}
 }
 
-func TestDeleteVoice(t *testing.T) {
+func TestStreamDownloadHistoryAudio(t *testing.T) {
+	expResponseBody := testRespBodies["TestDownloadHistoryAudio"]
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodDelete,
+		expectedMethod:      http.MethodPost,
 		expectedContentType: contentTypeJSON,
-		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
+		responseBody:        expResponseBody,
 	})
 	defer server.Close()
+
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	err := client.DeleteVoice("TestVoiceID")
+	w := bytes.Buffer{}
+	err := client.StreamDownloadHistoryAudio(&w, elevenlabs.DownloadHistoryRequest{HistoryItemIds: []string{"TestHistoryItemID1", "TestHistoryItemID2"}})
 	if err != nil {
-		t.Errorf("Expected no errors from `DeleteVoice`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors, got error: %q", err)
+	}
+
+	if w.String() != string(expResponseBody) {
+		t.Errorf("Expected response %q, got %q", string(expResponseBody), w.String())
 	}
 }
 
-func TestEditVoiceSettings(t *testing.T) {
+func TestGetSubscription(t *testing.T) {
+	respBody := testRespBodies["TestGetSubscription"]
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodPost,
+		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
+		responseBody:        respBody,
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	err := client.EditVoiceSettings("TestVoiceID", elevenlabs.VoiceSettings{Stability: 0.2, SimilarityBoost: 0.7, Style: 0.3, SpeakerBoost: false})
+	subscription, err := client.GetSubscription()
 	if err != nil {
-		t.Errorf("Expected no errors, got error: %q", err)
+		t.Errorf("Expected no errors from `GetSubscription`, got \"%T\" error: %q", err, err)
+	}
+	var expSub elevenlabs.Subscription
+	if err := json.Unmarshal(respBody, &expSub); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expSub, subscription) {
+		t.Errorf("Unexpected Subscription in response: %+v", subscription)
 	}
 }
 
-func TestAddVoice(t *testing.T) {
+func TestSubscriptionLimits(t *testing.T) {
+	var sub elevenlabs.Subscription
+	if err := json.Unmarshal(testRespBodies["TestSubscriptionLimits"], &sub); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	expSub := elevenlabs.Subscription{
+		Tier:                           "creator",
+		CharacterCount:                 48213,
+		CharacterLimit:                 100000,
+		CanExtendCharacterLimit:        true,
+		AllowedToExtendCharacterLimit:  false,
+		NextCharacterCountResetUnix:    1717200000,
+		VoiceSlotsUsed:                 28,
+		VoiceLimit:                     30,
+		MaxVoiceAddEdits:               95,
+		VoiceAddEditCounter:            12,
+		ProfessionalVoiceLimit:         1,
+		CanExtendVoiceLimit:            false,
+		CanUseInstantVoiceCloning:      true,
+		CanUseProfessionalVoiceCloning: true,
+		Currency:                       "usd",
+		Status:                         "active",
+	}
+	if !reflect.DeepEqual(expSub, sub) {
+		t.Errorf("Expected Subscription %+v, got %+v", expSub, sub)
+	}
+	if got := sub.RemainingVoiceSlots(); got != 2 {
+		t.Errorf("Expected 2 remaining voice slots, got %d", got)
+	}
+	sub.VoiceSlotsUsed = 31
+	if got := sub.RemainingVoiceSlots(); got != 0 {
+		t.Errorf("Expected 0 remaining voice slots when over the limit, got %d", got)
+	}
+}
+
+func TestModelValidation(t *testing.T) {
+	req := elevenlabs.TextToSpeechRequest{Text: "Test text"}.WithModel(elevenlabs.ModelTurboV2_5)
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal TextToSpeechRequest: %s", err)
+	}
+	if exp := `{"text":"Test text","model_id":"eleven_turbo_v2_5"}`; string(b) != exp {
+		t.Errorf("Expected request body %s, got %s", exp, b)
+	}
+
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
 	testCases := []struct {
-		name        string
-		paths       []string
-		expRespBody []byte
-		expError    bool
+		name       string
+		client     *elevenlabs.Client
+		modelID    string
+		expModelID string
+		expErr     bool
 	}{
-		{
-			name:        "with existing sample file",
-			paths:       []string{"testdata/fake.mp3"},
-			expRespBody: []byte(`{"voice_id":"TestVoiceId"}`),
-			expError:    false,
-		},
-		{
-			name:        "with non-existent sample file",
-			paths:       []string{"testdata/not-there.mp3"},
-			expRespBody: []byte("{}"),
-			expError:    true,
-		},
+		{name: "no validation", client: client},
+		{name: "default model", client: client.With(elevenlabs.WithModelValidation()), expModelID: elevenlabs.DefaultModel},
+		{name: "known model", client: client.With(elevenlabs.WithModelValidation()), modelID: elevenlabs.ModelFlashV2_5, expModelID: elevenlabs.ModelFlashV2_5},
+		{name: "speech-to-speech model", client: client.With(elevenlabs.WithModelValidation()), modelID: elevenlabs.ModelEnglishSTSV2, expErr: true},
+		{name: "unknown model", client: client.With(elevenlabs.WithModelValidation()), modelID: "eleven_unknown", expErr: true},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			request := elevenlabs.AddEditVoiceRequest{
-				Name:        "NewTestVoiceName",
-				FilePaths:   tc.paths,
-				Description: "New voice description here",
-				Labels:      map[string]string{"accent": "australian", "foo": "bar"},
-			}
-			server := testServer(t, testServerConfig{
-				expectedMethod:      http.MethodPost,
-				expectedContentType: contentMultipart,
-				expectedAccept:      "*/*",
-				statusCode:          http.StatusOK,
-				responseBody:        tc.expRespBody,
-			})
-			defer server.Close()
-			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-			id, err := client.AddVoice(request)
-			if err != nil {
-				if !tc.expError {
-					t.Errorf("Expected no errors, got error: %q", err)
+			_, err := tc.client.TextToSpeech("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}.WithModel(tc.modelID))
+			if tc.expErr {
+				if err == nil {
+					t.Fatal("Expected an error, got nil")
+				}
+				select {
+				case body := <-bodies:
+					t.Errorf("Expected no request to be sent, got %s", body)
+				default:
 				}
 				return
 			}
-			if tc.expError {
-				t.Error("Expected an error, got nil")
-				return
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			var sent elevenlabs.TextToSpeechRequest
+			if err := json.Unmarshal(<-bodies, &sent); err != nil {
+				t.Fatalf("Failed to unmarshal request body: %s", err)
+			}
+			if sent.ModelID != tc.expModelID {
+				t.Errorf("Expected model ID %q to be sent, got %q", tc.expModelID, sent.ModelID)
+			}
+		})
+	}
+
+	validating := client.With(elevenlabs.WithModelValidation())
+	for name, build := range map[string]func(string, elevenlabs.TextToSpeechRequest, ...elevenlabs.QueryFunc) (*http.Request, error){
+		"BuildTextToSpeechRequest":       validating.BuildTextToSpeechRequest,
+		"BuildTextToSpeechStreamRequest": validating.BuildTextToSpeechStreamRequest,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := build("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"}.WithModel("eleven_unknown")); err == nil {
+				t.Error("Expected an error for an unknown model, got nil")
+			}
+			req, err := build("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Test text"})
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			var built elevenlabs.TextToSpeechRequest
+			if err := json.NewDecoder(req.Body).Decode(&built); err != nil {
+				t.Fatalf("Failed to decode request body: %s", err)
 			}
-
-			if id != "TestVoiceId" {
-				t.Errorf("Expected AddVoice to return voice ID %q, got %q", "TestVoiceId", id)
+			if built.ModelID != elevenlabs.DefaultModel {
+				t.Errorf("Expected model ID %q in the built request, got %q", elevenlabs.DefaultModel, built.ModelID)
 			}
+		})
+	}
+}
+
+func TestCharacterCost(t *testing.T) {
+	testCases := []struct {
+		text    string
+		expCost int
+	}{
+		{text: "", expCost: 0},
+		{text: "Hello, world!", expCost: 13},
+		{text: "Grüße aus Köln", expCost: 14},
+		{text: "こんにちは", expCost: 5},
+		{text: "Wait <break time=\"1s\" /> 👋", expCost: 26},
+	}
+	for _, tc := range testCases {
+		req := elevenlabs.TextToSpeechRequest{Text: tc.text, PreviousText: "Not billed."}
+		if cost := req.CharacterCost(); cost != tc.expCost {
+			t.Errorf("Expected a cost of %d for %q, got %d", tc.expCost, tc.text, cost)
+		}
+	}
+
+	sub := elevenlabs.Subscription{CharacterCount: 9990, CharacterLimit: 10000}
+	if got := sub.RemainingCharacters(); got != 10 {
+		t.Errorf("Expected 10 remaining characters, got %d", got)
+	}
+	sub.CharacterCount = 10500
+	if got := sub.RemainingCharacters(); got != 0 {
+		t.Errorf("Expected 0 remaining characters when over the limit, got %d", got)
+	}
+}
 
+func TestSubscriptionTier(t *testing.T) {
+	testCases := []struct {
+		tier           string
+		characterLimit int
+		expTier        string
+		exp192kbps     bool
+		expPCM44100    bool
+	}{
+		{tier: "free", characterLimit: 10000, expTier: elevenlabs.TierFree},
+		{tier: "starter", characterLimit: 30000, expTier: elevenlabs.TierStarter},
+		{tier: "creator", characterLimit: 100000, expTier: elevenlabs.TierCreator, exp192kbps: true},
+		{tier: "Creator_Annual", expTier: elevenlabs.TierCreator, exp192kbps: true},
+		{tier: "pro", expTier: elevenlabs.TierPro, exp192kbps: true, expPCM44100: true},
+		{tier: "independent_publisher", expTier: elevenlabs.TierPro, exp192kbps: true, expPCM44100: true},
+		{tier: "growing_business", expTier: elevenlabs.TierScale, exp192kbps: true, expPCM44100: true},
+		{tier: "scale", expTier: elevenlabs.TierScale, exp192kbps: true, expPCM44100: true},
+		{tier: "business", expTier: elevenlabs.TierBusiness, exp192kbps: true, expPCM44100: true},
+		{tier: "enterprise", expTier: elevenlabs.TierEnterprise, exp192kbps: true, expPCM44100: true},
+		{tier: "custom_plan", characterLimit: 600000, expTier: elevenlabs.TierPro, exp192kbps: true, expPCM44100: true},
+		{tier: "", characterLimit: 10000, expTier: elevenlabs.TierFree},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tier, func(t *testing.T) {
+			sub := elevenlabs.Subscription{Tier: tc.tier, CharacterLimit: tc.characterLimit}
+			if got := sub.NormalizedTier(); got != tc.expTier {
+				t.Errorf("Expected tier %q, got %q", tc.expTier, got)
+			}
+			if got := sub.CanUse192kbps(); got != tc.exp192kbps {
+				t.Errorf("Expected CanUse192kbps to be %t, got %t", tc.exp192kbps, got)
+			}
+			if got := sub.CanUsePCM44100(); got != tc.expPCM44100 {
+				t.Errorf("Expected CanUsePCM44100 to be %t, got %t", tc.expPCM44100, got)
+			}
 		})
 	}
 }
 
-func TestEditVoice(t *testing.T) {
+func TestGetUser(t *testing.T) {
+	respBody := testRespBodies["TestGetUser"]
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodPost,
-		expectedContentType: contentMultipart,
+		expectedMethod:      http.MethodGet,
+		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
+		responseBody:        respBody,
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	err := client.EditVoice("TestVoiceID", elevenlabs.AddEditVoiceRequest{Name: "TestVoice"})
+	user, err := client.GetUser()
 	if err != nil {
-		t.Errorf("Expected no errors, got error: %q", err)
+		t.Errorf("Expected no errors from `GetUser`, got \"%T\" error: %q", err, err)
+	}
+	var expUser elevenlabs.User
+	if err := json.Unmarshal(respBody, &expUser); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expUser, user) {
+		t.Errorf("Unexpected User in response: %+v", user)
 	}
 }
 
-func TestDeleteSample(t *testing.T) {
-	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodDelete,
-		expectedContentType: contentTypeJSON,
-		expectedAccept:      "*/*",
-		statusCode:          http.StatusOK,
-	})
+func TestGetCharacterUsage(t *testing.T) {
+	queryCh := make(chan url.Values, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/usage/character-stats" {
+			t.Errorf("Server: unexpected path %q", r.URL.Path)
+		}
+		queryCh <- r.URL.Query()
+		w.Write(testRespBodies["TestGetCharacterUsage"])
+	}))
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	err := client.DeleteSample("TestVoiceID", "TestSampleID")
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	stats, err := client.GetCharacterUsage(start, end, elevenlabs.UsageBreakdown("voice"), elevenlabs.UsageAggregationInterval("day"))
 	if err != nil {
-		t.Errorf("Expected no errors from `DeleteSample`, got \"%T\" error: %q", err, err)
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	expQuery := url.Values{
+		"start_unix":           {"1714521600000"},
+		"end_unix":             {"1714694400000"},
+		"breakdown_type":       {"voice"},
+		"aggregation_interval": {"day"},
+	}
+	if gotQuery := <-queryCh; !reflect.DeepEqual(expQuery, gotQuery) {
+		t.Errorf("Expected query %v, got %v", expQuery, gotQuery)
+	}
+	if exp := []time.Time{start, start.Add(24 * time.Hour)}; !reflect.DeepEqual(exp, utcTimes(stats.Timestamps())) {
+		t.Errorf("Expected timestamps %v, got %v", exp, stats.Timestamps())
+	}
+	if got := stats.Total("VoiceID1"); got != 1500 {
+		t.Errorf("Expected a total of 1500 characters for VoiceID1, got %v", got)
+	}
+	if got := stats.Total("Unknown"); got != 0 {
+		t.Errorf("Expected a total of 0 characters for an unknown category, got %v", got)
 	}
 }
 
-func TestGetSampleAudio(t *testing.T) {
-	expRespBody := testRespBodies["TestGetSampleAudio"]
+// utcTimes returns ts converted to UTC.
+func utcTimes(ts []time.Time) []time.Time {
+	for i := range ts {
+		ts[i] = ts[i].UTC()
+	}
+	return ts
+}
 
+func TestGetProjects(t *testing.T) {
+	respBody := testRespBodies["TestGetProjects"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        []byte(expRespBody),
+		responseBody:        respBody,
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	respBody, err := client.GetSampleAudio("TestVoiceID", "TestSampleID")
+	projects, err := client.GetProjects()
 	if err != nil {
-		t.Errorf("Expected no errors from `GetSampleAudio`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `GetProjects`, got \"%T\" error: %q", err, err)
 	}
-	if string(respBody) != string(expRespBody) {
-		t.Errorf("Expected response %q, got %q", string(expRespBody), string(respBody))
+	var expResp elevenlabs.GetProjectsResponse
+	if err := json.Unmarshal(respBody, &expResp); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expResp.Projects, projects) {
+		t.Errorf("Unexpected Projects in response: %+v", projects)
 	}
 }
 
-func TestGetHistory(t *testing.T) {
+func TestCreateProject(t *testing.T) {
 	testCases := []struct {
-		name           string
-		queries        []elevenlabs.QueryFunc
-		expQueryString string
-		expStatus      int
-		respBody       []byte
-		expNextFunc    bool
+		name     string
+		document string
+		expError bool
 	}{
-		{
-			name:           "setting relevant queries",
-			queries:        []elevenlabs.QueryFunc{elevenlabs.PageSize(50), elevenlabs.StartAfter("fake-history-id")},
-			expQueryString: "page_size=50&start_after_history_item_id=fake-history-id",
-			respBody:       []byte("{}"),
-		},
-		{
-			name:        "return nil for next page function when has_more is false",
-			respBody:    testRespBodies["TestGetHistory-NoMore"],
-			expNextFunc: false,
-		},
-		{
-			name:        "return next page function when has_more is true",
-			respBody:    testRespBodies["TestGetHistory-HasMore"],
-			expNextFunc: true,
-		},
+		{name: "with existing document", document: "testdata/fake.txt"},
+		{name: "with non-existent document", document: "testdata/not-there.txt", expError: true},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			// mockResponse := testRespBodies["TestGetHistory"]
-			config := testServerConfig{
-				keyOptional:         false,
-				expectedMethod:      "GET",
-				expectedContentType: "application/json",
-				expectedAccept:      "*/*",
-				expectedQueryStr:    tc.expQueryString,
-				statusCode:          http.StatusOK,
-				responseBody:        tc.respBody,
-				responseDelay:       0,
-			}
-			server := testServer(t, config)
+			formCh := make(chan map[string][]string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Errorf("Server: failed to parse multipart form: %s", err)
+				}
+				if _, ok := r.MultipartForm.File["from_document"]; !ok {
+					t.Error("Server: expected a from_document file")
+				}
+				formCh <- r.MultipartForm.Value
+				w.Header().Set("Content-Type", contentTypeJSON)
+				w.Write([]byte(`{"project":{"project_id":"TestProjectID","name":"TestProject","state":"default"}}`))
+			}))
 			defer server.Close()
 			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-
-			resp, nextPageFunc, err := client.GetHistory(tc.queries...)
-			if err != nil {
-				t.Fatalf("Expected GetHistory to return no error, got %q", err)
-			}
-
-			expectedResp := elevenlabs.GetHistoryResponse{}
-			err = json.Unmarshal(tc.respBody, &expectedResp)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			if !reflect.DeepEqual(resp, expectedResp) {
-				t.Errorf("Expected GetHistory to return response %+v, got %+v", expectedResp, resp)
-			}
-
-			if !tc.expNextFunc {
-				if nextPageFunc != nil {
-					t.Error("Expected GetHistory to return a nil next page function, but it didn't")
+			project, err := client.CreateProject(elevenlabs.CreateProjectRequest{
+				Name:                    "TestProject",
+				DefaultTitleVoiceId:     "TestVoiceID",
+				DefaultParagraphVoiceId: "TestVoiceID",
+				DefaultModelId:          elevenlabs.ModelMultilingualV2,
+				FromDocument:            tc.document,
+				Author:                  "Test Author",
+			})
+			if tc.expError {
+				if err == nil {
+					t.Error("Expected an error, got nil")
 				}
 				return
 			}
-
-			nextResp, _, err := nextPageFunc(elevenlabs.PageSize(99))
 			if err != nil {
-				t.Fatalf("Expected GetHistory to return no error, got %q", err)
+				t.Fatalf("Expected no errors, got error: %q", err)
 			}
-
-			expectedNextResp := elevenlabs.GetHistoryResponse{}
-			err = json.Unmarshal(tc.respBody, &expectedNextResp)
-			if err != nil {
-				t.Fatal(err)
+			if project.ProjectId != "TestProjectID" {
+				t.Errorf("Expected project ID %q, got %q", "TestProjectID", project.ProjectId)
 			}
-
-			if !reflect.DeepEqual(nextResp, expectedNextResp) {
-				t.Errorf("Expected calling next function to return response %+v, got %+v", expectedNextResp, nextResp)
+			expForm := map[string][]string{
+				"name":                       {"TestProject"},
+				"default_title_voice_id":     {"TestVoiceID"},
+				"default_paragraph_voice_id": {"TestVoiceID"},
+				"default_model_id":           {elevenlabs.ModelMultilingualV2},
+				"author":                     {"Test Author"},
+				"volume_normalization":       {"false"},
+			}
+			if gotForm := <-formCh; !reflect.DeepEqual(expForm, gotForm) {
+				t.Errorf("Expected multipart form values %q, got %q", expForm, gotForm)
 			}
 		})
 	}
 }
 
-func TestGetHistoryItem(t *testing.T) {
-	respBody := testRespBodies["TestGetHistoryItem"]
+func TestGetProject(t *testing.T) {
+	respBody := testRespBodies["TestGetProject"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
@@ -736,20 +4857,23 @@ func TestGetHistoryItem(t *testing.T) {
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	historyItem, err := client.GetHistoryItem("TestHistoryItemID")
+	project, err := client.GetProject("TestProjectID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetHistoryItem`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `GetProject`, got \"%T\" error: %q", err, err)
 	}
-	var expItem elevenlabs.HistoryItem
-	if err := json.Unmarshal(respBody, &expItem); err != nil {
+	var expProject elevenlabs.Project
+	if err := json.Unmarshal(respBody, &expProject); err != nil {
 		t.Fatalf("Failed to unmarshal test respBody: %s", err)
 	}
-	if !reflect.DeepEqual(expItem, historyItem) {
-		t.Errorf("Unexpected HistoryItem in response: %+v", historyItem)
+	if !reflect.DeepEqual(expProject, project) {
+		t.Errorf("Unexpected Project in response: %+v", project)
+	}
+	if !project.IsConverting() || len(project.Chapters) != 1 {
+		t.Errorf("Expected a converting project with one chapter, got %+v", project)
 	}
 }
 
-func TestDeleteHistoryItem(t *testing.T) {
+func TestDeleteProject(t *testing.T) {
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodDelete,
 		expectedContentType: contentTypeJSON,
@@ -758,56 +4882,54 @@ func TestDeleteHistoryItem(t *testing.T) {
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	err := client.DeleteHistoryItem("TestHistoryItemID")
+	err := client.DeleteProject("TestProjectID")
 	if err != nil {
-		t.Errorf("Expected no errors from `DeleteHistoryItem`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `DeleteProject`, got \"%T\" error: %q", err, err)
 	}
 }
 
-func TestGetHistoryItemAudio(t *testing.T) {
-	expRespBody := testRespBodies["TestGetHistoryItemAudio"]
+func TestConvertProject(t *testing.T) {
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodGet,
+		expectedMethod:      http.MethodPost,
 		expectedContentType: contentTypeJSON,
 		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        []byte(expRespBody),
+		responseBody:        []byte(`{"status":"ok"}`),
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	respBody, err := client.GetHistoryItemAudio("TestHistoryItemID")
+	err := client.ConvertProject("TestProjectID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetHistoryItemAudio`, got \"%T\" error: %q", err, err)
-	}
-	if string(respBody) != string(expRespBody) {
-		t.Errorf("Expected response %q, got %q", string(expRespBody), string(respBody))
+		t.Errorf("Expected no errors from `ConvertProject`, got \"%T\" error: %q", err, err)
 	}
 }
 
-func TestDownloadHistoryAudio(t *testing.T) {
-	expResponseBody := testRespBodies["TestDownloadHistoryAudio"]
+func TestGetProjectChapters(t *testing.T) {
+	respBody := testRespBodies["TestGetProjectChapters"]
 	server := testServer(t, testServerConfig{
-		expectedMethod:      http.MethodPost,
+		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
+		expectedAccept:      "*/*",
 		statusCode:          http.StatusOK,
-		responseBody:        expResponseBody,
+		responseBody:        respBody,
 	})
 	defer server.Close()
-
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	respBody, err := client.DownloadHistoryAudio(elevenlabs.DownloadHistoryRequest{HistoryItemIds: []string{"TestHistoryItemID"}})
-
+	chapters, err := client.GetProjectChapters("TestProjectID")
 	if err != nil {
-		t.Errorf("Expected no errors, got error: %q", err)
+		t.Errorf("Expected no errors from `GetProjectChapters`, got \"%T\" error: %q", err, err)
 	}
-
-	if string(respBody) != string(expResponseBody) {
-		t.Errorf("Expected response %q, got %q", string(expResponseBody), string(respBody))
+	var expResp elevenlabs.GetChaptersResponse
+	if err := json.Unmarshal(respBody, &expResp); err != nil {
+		t.Fatalf("Failed to unmarshal test respBody: %s", err)
+	}
+	if !reflect.DeepEqual(expResp.Chapters, chapters) {
+		t.Errorf("Unexpected Chapters in response: %+v", chapters)
 	}
 }
 
-func TestGetSubscription(t *testing.T) {
-	respBody := testRespBodies["TestGetSubscription"]
+func TestGetChapterSnapshots(t *testing.T) {
+	respBody := testRespBodies["TestGetChapterSnapshots"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
@@ -817,21 +4939,21 @@ func TestGetSubscription(t *testing.T) {
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	subscription, err := client.GetSubscription()
+	snapshots, err := client.GetChapterSnapshots("TestProjectID", "TestChapterID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetSubscription`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `GetChapterSnapshots`, got \"%T\" error: %q", err, err)
 	}
-	var expSub elevenlabs.Subscription
-	if err := json.Unmarshal(respBody, &expSub); err != nil {
+	var expResp elevenlabs.GetChapterSnapshotsResponse
+	if err := json.Unmarshal(respBody, &expResp); err != nil {
 		t.Fatalf("Failed to unmarshal test respBody: %s", err)
 	}
-	if !reflect.DeepEqual(expSub, subscription) {
-		t.Errorf("Unexpected Subscription in response: %+v", subscription)
+	if !reflect.DeepEqual(expResp.Snapshots, snapshots) {
+		t.Errorf("Unexpected Snapshots in response: %+v", snapshots)
 	}
 }
 
-func TestGetUser(t *testing.T) {
-	respBody := testRespBodies["TestGetUser"]
+func TestGetProjectSnapshots(t *testing.T) {
+	respBody := testRespBodies["TestGetProjectSnapshots"]
 	server := testServer(t, testServerConfig{
 		expectedMethod:      http.MethodGet,
 		expectedContentType: contentTypeJSON,
@@ -841,15 +4963,146 @@ func TestGetUser(t *testing.T) {
 	})
 	defer server.Close()
 	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
-	user, err := client.GetUser()
+	snapshots, err := client.GetProjectSnapshots("TestProjectID")
 	if err != nil {
-		t.Errorf("Expected no errors from `GetUser`, got \"%T\" error: %q", err, err)
+		t.Errorf("Expected no errors from `GetProjectSnapshots`, got \"%T\" error: %q", err, err)
 	}
-	var expUser elevenlabs.User
-	if err := json.Unmarshal(respBody, &expUser); err != nil {
+	var expResp elevenlabs.GetProjectSnapshotsResponse
+	if err := json.Unmarshal(respBody, &expResp); err != nil {
 		t.Fatalf("Failed to unmarshal test respBody: %s", err)
 	}
-	if !reflect.DeepEqual(expUser, user) {
-		t.Errorf("Unexpected User in response: %+v", user)
+	if !reflect.DeepEqual(expResp.Snapshots, snapshots) {
+		t.Errorf("Unexpected Snapshots in response: %+v", snapshots)
+	}
+}
+
+func TestDownloadProjectSnapshot(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		expNotReady bool
+	}{
+		{name: "converted", status: http.StatusOK},
+		{name: "conversion not ready", status: http.StatusConflict, expNotReady: true},
+		{name: "other error", status: http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expAudio := testRespBodies["TestDownloadProjectSnapshot"]
+			server := testServer(t, testServerConfig{
+				expectedMethod:      http.MethodPost,
+				expectedContentType: contentTypeJSON,
+				expectedAccept:      "*/*",
+				statusCode:          tc.status,
+				responseBody:        expAudio,
+			})
+			defer server.Close()
+			client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+			w := bytes.Buffer{}
+			err := client.DownloadProjectSnapshot("TestProjectID", "TestSnapshotID", &w)
+			var notReadyErr *elevenlabs.NotReadyError
+			if gotNotReady := errors.As(err, &notReadyErr); gotNotReady != tc.expNotReady {
+				t.Errorf("Expected NotReadyError to be %t, got error %v", tc.expNotReady, err)
+			}
+			if tc.status != http.StatusOK {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no errors, got error: %q", err)
+			}
+			if !bytes.Equal(w.Bytes(), expAudio) {
+				t.Errorf("Expected audio %q, got %q", expAudio, w.Bytes())
+			}
+		})
+	}
+}
+
+// dialogServer serves stream-input sessions, replying with the audio "<voice ID>:<text>". The sessions of
+// the voice "slow" are delayed, and the ones of the voice "bad" fail.
+func dialogServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var upgrader websocket.Upgrader
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		voiceID := strings.Split(r.URL.Path, "/")[2]
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Server: failed to upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		if voiceID == "bad" {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "invalid voice"))
+			return
+		}
+		var texts []string
+		for {
+			var msg map[string]any
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			text, _ := msg["text"].(string)
+			if text == "" {
+				break
+			}
+			texts = append(texts, text)
+		}
+		if voiceID == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		audio := voiceID + ":" + strings.TrimSpace(strings.Join(texts, "")) + " "
+		conn.WriteJSON(map[string]any{"audio": base64.StdEncoding.EncodeToString([]byte(audio))})
+		conn.WriteJSON(map[string]any{"isFinal": true})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+}
+
+func TestDialog(t *testing.T) {
+	server := dialogServer(t)
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	dialog := client.NewDialog(elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	dialog.Add("slow", "Hello there.").Add("fast", "Hi!").Add("slow", "How are you?")
+	if n := len(dialog.Turns()); n != 3 {
+		t.Fatalf("Expected 3 enqueued turns, got %d", n)
+	}
+
+	audio := bytes.Buffer{}
+	if err := dialog.Stream(&audio); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if exp := "slow:Hello there. fast:Hi! slow:How are you? "; audio.String() != exp {
+		t.Errorf("Expected audio %q, got %q", exp, audio.String())
+	}
+	if n := len(dialog.Turns()); n != 0 {
+		t.Errorf("Expected the turns to be dequeued, got %d turns", n)
+	}
+
+	audio.Reset()
+	if err := dialog.Add("fast", "Bye.").Stream(&audio); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if exp := "fast:Bye. "; audio.String() != exp {
+		t.Errorf("Expected audio %q, got %q", exp, audio.String())
+	}
+}
+
+func TestDialogFailedTurn(t *testing.T) {
+	server := dialogServer(t)
+	defer server.Close()
+
+	client := elevenlabs.NewMockWSClient(context.Background(), wsURL(server), mockAPIKey, mockTimeout)
+	dialog := client.NewDialog(elevenlabs.ModelTurboV2_5, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	dialog.Add("slow", "Hello.").Add("bad", "Hi!").Add("fast", "Bye.")
+
+	audio := bytes.Buffer{}
+	if err := dialog.Stream(&audio); err == nil {
+		t.Fatal("Expected an error for the failed turn, got nil")
+	}
+	if exp := "slow:Hello. "; audio.String() != exp {
+		t.Errorf("Expected only the audio of the turns before the failed one, got %q", audio.String())
 	}
 }
@@ -0,0 +1,320 @@
+// Package elevenlabstest provides fake ElevenLabs API servers for testing code that uses the elevenlabs package
+// without calling the actual API.
+//
+// A FakeServer stubs the common REST endpoints and records the requests it receives:
+//
+//	server := elevenlabstest.NewFakeServer()
+//	defer server.Close()
+//	client := server.Client()
+//	audio, err := client.TextToSpeech("voiceID", elevenlabs.TextToSpeechRequest{Text: "Hello"})
+//	// audio is elevenlabstest.Audio, and server.Requests() holds the request that was sent.
+//
+// A FakeWSServer replies to the stream-input sessions of TextToSpeechInputStream with scripted messages.
+package elevenlabstest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/clearlyip/elevenlabs-go"
+	"github.com/gorilla/websocket"
+)
+
+// APIKey is the API key of the clients returned by FakeServer.Client and FakeWSServer.Client. The fake servers
+// accept any non-empty API key.
+const APIKey = "fake-api-key"
+
+// Audio is the audio returned by the fake text-to-speech and history audio endpoints.
+var Audio = []byte("fake audio")
+
+// Fake resources returned by the stubbed endpoints of FakeServer.
+var (
+	Model = elevenlabs.Model{
+		ModelId:                            elevenlabs.ModelMultilingualV2,
+		Name:                               "Eleven Multilingual v2",
+		CanDoTextToSpeech:                  true,
+		Languages:                          []elevenlabs.Language{{LanguageId: "en", Name: "English"}},
+		MaxCharactersRequestFreeUser:       2500,
+		MaxCharactersRequestSubscribedUser: 5000,
+		MaximumTextLengthPerRequest:        10000,
+	}
+	VoiceSettings = elevenlabs.NewVoiceSettings(0.5, 0.75)
+	Voice         = elevenlabs.Voice{VoiceId: "fake-voice-id", Name: "Fake Voice", Category: "premade", Settings: VoiceSettings}
+	Subscription  = elevenlabs.Subscription{Tier: "free", CharacterLimit: 10000, VoiceLimit: 3, Status: "free"}
+)
+
+// Request is a request received by a fake server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// FakeServer is a fake of the ElevenLabs REST API. Its zero value is not usable, it must be created with
+// NewFakeServer.
+type FakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   []route
+	requests []Request
+}
+
+type route struct {
+	method, pattern string
+	handler         http.HandlerFunc
+}
+
+// NewFakeServer starts and returns a FakeServer, which must be closed with Close once done.
+//
+// It stubs the following endpoints, which respond with the fake resources of this package: GET /models,
+// GET /voices, GET /voices/{voice_id}, GET /voices/settings/default, GET /voices/{voice_id}/settings,
+// POST /text-to-speech/{voice_id}, POST /text-to-speech/{voice_id}/stream, GET /history,
+// GET /history/{history_item_id}/audio, DELETE /history/{history_item_id}, GET /user and
+// GET /user/subscription. Other endpoints respond with a 404 status, unless stubbed with Handle or HandleFunc.
+//
+// Requests without an API key are rejected with a 401 status, as the API does.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{}
+	s.Handle(http.MethodGet, "/models", http.StatusOK, mustMarshal([]elevenlabs.Model{Model}))
+	s.Handle(http.MethodGet, "/voices", http.StatusOK, mustMarshal(elevenlabs.GetVoicesResponse{Voices: []elevenlabs.Voice{Voice}}))
+	s.Handle(http.MethodGet, "/voices/{voice_id}", http.StatusOK, mustMarshal(Voice))
+	s.Handle(http.MethodGet, "/voices/settings/default", http.StatusOK, mustMarshal(VoiceSettings))
+	s.Handle(http.MethodGet, "/voices/{voice_id}/settings", http.StatusOK, mustMarshal(VoiceSettings))
+	s.Handle(http.MethodPost, "/text-to-speech/{voice_id}", http.StatusOK, Audio)
+	s.Handle(http.MethodPost, "/text-to-speech/{voice_id}/stream", http.StatusOK, Audio)
+	s.Handle(http.MethodGet, "/history", http.StatusOK, mustMarshal(elevenlabs.GetHistoryResponse{History: []elevenlabs.HistoryItem{}}))
+	s.Handle(http.MethodGet, "/history/{history_item_id}/audio", http.StatusOK, Audio)
+	s.Handle(http.MethodDelete, "/history/{history_item_id}", http.StatusOK, []byte(`{"status":"ok"}`))
+	s.Handle(http.MethodGet, "/user", http.StatusOK, mustMarshal(elevenlabs.User{Subscription: Subscription}))
+	s.Handle(http.MethodGet, "/user/subscription", http.StatusOK, mustMarshal(Subscription))
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Handle stubs the endpoint at the given method and path, to which the server then responds with the given status
+// and body. Path segments enclosed in braces, e.g. "/voices/{voice_id}", match any value, and paths with fewer such
+// wildcards take precedence. Stubs replace those previously set for the same method and path, including the
+// default ones.
+func (s *FakeServer) Handle(method, path string, status int, body []byte) {
+	s.HandleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK || json.Valid(body) {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(status)
+		w.Write(body)
+	})
+}
+
+// HandleFunc stubs the endpoint at the given method and path with a handler, like Handle. The body of the request
+// is recorded before the handler is called and can still be read by it.
+func (s *FakeServer) HandleFunc(method, path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, rt := range s.routes {
+		if rt.method == method && rt.pattern == path {
+			s.routes[i].handler = handler
+			return
+		}
+	}
+	s.routes = append(s.routes, route{method: method, pattern: path, handler: handler})
+}
+
+// Client returns a client that sends its requests to the server, configured with the given options.
+func (s *FakeServer) Client(opts ...elevenlabs.Option) *elevenlabs.Client {
+	return elevenlabs.NewClientWithOptions(APIKey, append([]elevenlabs.Option{elevenlabs.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// Requests returns the requests received by the server, in the order they were received.
+func (s *FakeServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// AssertRequested fails the test if the server didn't receive a request with the given method and path, which may
+// contain the same wildcards as with Handle. It returns the last matching request.
+func (s *FakeServer) AssertRequested(t testing.TB, method, path string) Request {
+	t.Helper()
+	requests := s.Requests()
+	for i := len(requests) - 1; i >= 0; i-- {
+		if requests[i].Method == method && matchPath(path, requests[i].Path) {
+			return requests[i]
+		}
+	}
+	t.Errorf("Expected a %s %s request, got %d other requests", method, path, len(requests))
+	return Request{}
+}
+
+func (s *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+	// The most specific route, with the fewest wildcards, takes precedence.
+	var handler http.HandlerFunc
+	minWildcards := -1
+	for _, rt := range s.routes {
+		if rt.method != r.Method || !matchPath(rt.pattern, r.URL.Path) {
+			continue
+		}
+		if n := strings.Count(rt.pattern, "{"); minWildcards < 0 || n < minWildcards {
+			handler, minWildcards = rt.handler, n
+		}
+	}
+	s.mu.Unlock()
+
+	switch {
+	case r.Header.Get("xi-api-key") == "":
+		writeAPIError(w, http.StatusUnauthorized, "needs_authorization", "Neither authorization header nor xi-api-key received, please provide one.")
+	case handler == nil:
+		writeAPIError(w, http.StatusNotFound, "not_found", "Not Found")
+	default:
+		handler(w, r)
+	}
+}
+
+// matchPath reports whether path matches pattern, in which segments enclosed in braces match any value.
+func matchPath(pattern, path string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegs) != len(pathSegs) {
+		return false
+	}
+	for i, seg := range patternSegs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			continue
+		}
+		if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeAPIError writes an error response in the format of elevenlabs.APIError.
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(mustMarshal(elevenlabs.APIError{Detail: elevenlabs.APIErrorDetail{Status: code, Message: message}}))
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// FakeWSServer is a fake of the stream-input WebSocket endpoint used by TextToSpeechInputStream. Its zero value is
+// not usable, it must be created with NewFakeWSServer.
+type FakeWSServer struct {
+	*httptest.Server
+
+	frames   []elevenlabs.StreamingInputResponse
+	mu       sync.Mutex
+	messages [][]map[string]interface{}
+}
+
+// NewFakeWSServer starts and returns a FakeWSServer, which must be closed with Close once done.
+//
+// For every connection, the server reads the messages sent by the client until the end of the text, signaled by a
+// message with an empty text, then replies with the given frames in order and closes the connection. A final
+// message is sent after the frames, unless one of them has IsFinal set.
+func NewFakeWSServer(frames ...elevenlabs.StreamingInputResponse) *FakeWSServer {
+	s := &FakeWSServer{frames: frames}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveWS))
+	return s
+}
+
+// AudioFrame returns a frame carrying the given audio, which is base64 encoded as the API does.
+func AudioFrame(audio []byte) elevenlabs.StreamingInputResponse {
+	return elevenlabs.StreamingInputResponse{Audio: base64.StdEncoding.EncodeToString(audio)}
+}
+
+// Client returns a client that connects to the server, configured with the given options.
+func (s *FakeWSServer) Client(opts ...elevenlabs.Option) *elevenlabs.Client {
+	return elevenlabs.NewClientWithOptions(APIKey, append([]elevenlabs.Option{elevenlabs.WithBaseWSURL(s.WSURL())}, opts...)...)
+}
+
+// WSURL returns the WebSocket URL of the server, to be used with elevenlabs.WithBaseWSURL.
+func (s *FakeWSServer) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Messages returns the JSON messages received on each connection, in the order the connections were made.
+func (s *FakeWSServer) Messages() [][]map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]map[string]interface{}(nil), s.messages...)
+}
+
+// Texts returns the text of the messages received on each connection, in the order the connections were made.
+// Messages with only whitespace, such as the initial message of a session, keep-alives and the empty text that
+// ends a session, are left out.
+func (s *FakeWSServer) Texts() [][]string {
+	var texts [][]string
+	for _, conn := range s.Messages() {
+		var connTexts []string
+		for _, msg := range conn {
+			if text, _ := msg["text"].(string); strings.TrimSpace(text) != "" {
+				connTexts = append(connTexts, text)
+			}
+		}
+		texts = append(texts, connTexts)
+	}
+	return texts
+}
+
+func (s *FakeWSServer) serveWS(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("xi-api-key") == "" {
+		writeAPIError(w, http.StatusUnauthorized, "needs_authorization", "Neither authorization header nor xi-api-key received, please provide one.")
+		return
+	}
+	var upgrader websocket.Upgrader
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	n := len(s.messages)
+	s.messages = append(s.messages, nil)
+	s.mu.Unlock()
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.messages[n] = append(s.messages[n], msg)
+		s.mu.Unlock()
+		if text, _ := msg["text"].(string); text == "" {
+			break
+		}
+	}
+
+	final := false
+	for _, frame := range s.frames {
+		if err := conn.WriteJSON(frame); err != nil {
+			return
+		}
+		final = final || frame.IsFinal
+	}
+	if !final {
+		conn.WriteJSON(elevenlabs.StreamingInputResponse{IsFinal: true})
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
@@ -0,0 +1,92 @@
+package elevenlabstest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/clearlyip/elevenlabs-go"
+	"github.com/clearlyip/elevenlabs-go/elevenlabstest"
+)
+
+func TestFakeServer(t *testing.T) {
+	server := elevenlabstest.NewFakeServer()
+	defer server.Close()
+	client := server.Client()
+
+	models, err := client.GetModels()
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetModels`, got error: %q", err)
+	}
+	if !reflect.DeepEqual([]elevenlabs.Model{elevenlabstest.Model}, models) {
+		t.Errorf("Expected the fake model, got %+v", models)
+	}
+	audio, err := client.TextToSpeech("TestVoiceID", elevenlabs.TextToSpeechRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("Expected no errors from `TextToSpeech`, got error: %q", err)
+	}
+	if !bytes.Equal(elevenlabstest.Audio, audio) {
+		t.Errorf("Expected the fake audio, got %q", audio)
+	}
+
+	req := server.AssertRequested(t, http.MethodPost, "/text-to-speech/{voice_id}")
+	if req.Path != "/text-to-speech/TestVoiceID" || !bytes.Contains(req.Body, []byte(`"text":"Hello"`)) {
+		t.Errorf("Unexpected request recorded: %s %q", req.Path, req.Body)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("Expected 2 requests to be recorded, got %d", n)
+	}
+}
+
+func TestFakeServerHandle(t *testing.T) {
+	server := elevenlabstest.NewFakeServer()
+	defer server.Close()
+	server.Handle(http.MethodGet, "/voices/missing", http.StatusNotFound, []byte(`{"detail":{"status":"voice_not_found","message":"Voice not found"}}`))
+	client := server.Client()
+
+	if _, err := client.GetVoice("missing"); !errors.Is(err, elevenlabs.ErrNotFound) {
+		t.Errorf("Expected an ErrNotFound error for the stubbed endpoint, got %v", err)
+	}
+	voice, err := client.GetVoice("other")
+	if err != nil {
+		t.Fatalf("Expected no errors from `GetVoice`, got error: %q", err)
+	}
+	if voice.VoiceId != elevenlabstest.Voice.VoiceId {
+		t.Errorf("Expected the fake voice, got %+v", voice)
+	}
+	if _, err := client.GetProjects(); err == nil {
+		t.Error("Expected an error for an endpoint that isn't stubbed, got nil")
+	}
+	if _, err := client.With(elevenlabs.WithoutAPIKey()).GetModels(); err == nil {
+		t.Error("Expected an error for a request without an API key, got nil")
+	}
+}
+
+func TestFakeWSServer(t *testing.T) {
+	server := elevenlabstest.NewFakeWSServer(
+		elevenlabstest.AudioFrame([]byte("first")),
+		elevenlabstest.AudioFrame([]byte("second")),
+	)
+	defer server.Close()
+	client := server.Client()
+
+	text := make(chan string)
+	go func() {
+		defer close(text)
+		text <- "Hello "
+		text <- "world. "
+	}()
+	var audio bytes.Buffer
+	err := client.TextToSpeechInputStream(text, nil, &audio, "TestVoiceID", elevenlabs.ModelTurboV2, elevenlabs.TextToSpeechInputStreamingRequest{Text: " "})
+	if err != nil {
+		t.Fatalf("Expected no errors from `TextToSpeechInputStream`, got error: %q", err)
+	}
+	if audio.String() != "firstsecond" {
+		t.Errorf("Expected the scripted audio, got %q", audio.String())
+	}
+	if exp := [][]string{{"Hello ", "world. "}}; !reflect.DeepEqual(exp, server.Texts()) {
+		t.Errorf("Expected texts %q, got %q", exp, server.Texts())
+	}
+}
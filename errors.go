@@ -1,15 +1,68 @@
 package elevenlabs
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
+// ErrNotFound is matched by errors.Is for errors caused by the API responding with a 404 status, for instance
+// when retrieving a voice or history item that was deleted.
+var ErrNotFound = errors.New("not found")
+
+// ErrQuotaExceeded is matched by errors.Is for errors caused by the API rejecting a request because the character
+// quota of the subscription is used up, which it reports with a detail status of "quota_exceeded". The request
+// can succeed once the quota is reset or the subscription is upgraded, see GetSubscription.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrCircuitOpen is returned, without any request being sent, by the methods of a client configured with
+// WithCircuitBreaker while its circuit is open after too many consecutive failures.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrMissingAPIKey is returned, before any request is sent, by the methods of a client that has no API key. The
+// API would reject the request with a 401 status otherwise. See WithoutAPIKey to send requests without one.
+var ErrMissingAPIKey = errors.New("missing API key")
+
+// ErrNoPreview is returned by GetVoicePreview for voices that have no preview audio.
+var ErrNoPreview = errors.New("voice has no preview")
+
+// ErrStreamEndedEarly is returned by TextToSpeechInputStream when the API ends a stream-input session, e.g. with
+// its final message, while the text channel is still open, so that the remaining text would not be converted.
+// The session is reconnected instead if the client was configured with WithStreamReconnect.
+var ErrStreamEndedEarly = errors.New("stream-input session ended before all the text was sent")
+
+// ErrResponseTooLarge is matched by errors.Is for errors caused by a response body exceeding the limit set with
+// WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrInvalidWebhookSignature is matched by errors.Is for the errors returned by VerifyWebhookSignature and
+// ParseWebhookEvent when a webhook request wasn't signed with the expected secret, or was signed too long ago.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// errNotModified is returned by doRequestWithHeader when the API responds to a conditional request with 304
+// Not Modified.
+var errNotModified = errors.New("not modified")
+
+// StatusCoder is implemented by the errors that carry the HTTP status code of an API response, so that the
+// status code can be retrieved with errors.As:
+//
+//	var sc elevenlabs.StatusCoder
+//	if errors.As(err, &sc) {
+//		log.Printf("request failed with status %d", sc.StatusCode())
+//	}
+type StatusCoder interface {
+	StatusCode() int
+}
+
 // APIError represents an error response from the API.
 //
 // At this stage, any error that is not a ValidationError is returned in this format.
 type APIError struct {
 	Detail APIErrorDetail `json:"detail"`
+	// HTTPStatus is the status code of the response the error was returned with.
+	HTTPStatus int `json:"-"`
 }
 
 // APIErrorDetail contains detailed information about an APIError.
@@ -23,17 +76,76 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("api error - %s", e.Detail.Message)
 }
 
-// ValidationError represents a request validation error response from the API.
+// StatusCode returns the HTTP status code of the response the error was returned with.
+func (e *APIError) StatusCode() int {
+	return e.HTTPStatus
+}
+
+// Is reports whether the error matches target, which is the case for ErrQuotaExceeded if the character quota is
+// used up.
+func (e *APIError) Is(target error) bool {
+	return target == ErrQuotaExceeded && isQuotaExceeded(&e.Detail)
+}
+
+// quotaExceededStatuses are the detail statuses with which the API reports that the character quota is used up.
+var quotaExceededStatuses = []string{"quota_exceeded"}
+
+// isQuotaExceeded reports whether detail is the one of an error reporting that the character quota is used up.
+func isQuotaExceeded(detail *APIErrorDetail) bool {
+	if detail == nil {
+		return false
+	}
+	for _, status := range quotaExceededStatuses {
+		if detail.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError represents a request validation error response from the API, which lists the fields of the
+// request that failed validation, e.g.:
+//
+//	{"detail": [{"loc": ["body", "text"], "msg": "field required", "type": "value_error.missing"}]}
 type ValidationError struct {
 	Detail *[]ValidationErrorDetailItem `json:"detail"`
+	// HTTPStatus is the status code of the response the error was returned with.
+	HTTPStatus int `json:"-"`
 }
 
+// ValidationErrorDetailItem describes a field that failed validation. Loc is the location of the field, starting
+// with the part of the request it's in, e.g. "body", followed by its path, Msg describes the error and Type
+// identifies it, e.g. "value_error.missing".
 type ValidationErrorDetailItem struct {
 	Loc  []ValidationErrorDetailLocItem `json:"loc"`
 	Msg  string                         `json:"msg"`
 	Type string                         `json:"type"`
 }
 
+// Field returns the path of the field that failed validation, e.g. "voice_settings.stability", without the part
+// of the request it's in. Array indices are included as path elements.
+func (i ValidationErrorDetailItem) Field() string {
+	loc := i.Loc
+	if len(loc) > 1 {
+		switch loc[0] {
+		case "body", "query", "path", "header", "cookie":
+			loc = loc[1:]
+		}
+	}
+	parts := make([]string, len(loc))
+	for j, l := range loc {
+		parts[j] = string(l)
+	}
+	return strings.Join(parts, ".")
+}
+
+func (i ValidationErrorDetailItem) String() string {
+	if field := i.Field(); field != "" {
+		return field + ": " + i.Msg
+	}
+	return i.Msg
+}
+
 type ValidationErrorDetailLocItem string
 
 func (i *ValidationErrorDetailLocItem) UnmarshalJSON(b []byte) error {
@@ -42,8 +154,167 @@ func (i *ValidationErrorDetailLocItem) UnmarshalJSON(b []byte) error {
 }
 
 func (e *ValidationError) Error() string {
-	if (*e).Detail != nil && len(*e.Detail) > 0 {
-		return fmt.Sprintf("validation error - %s", (*e.Detail)[0].Msg)
+	if e.Detail == nil || len(*e.Detail) == 0 {
+		return "validation error"
+	}
+	items := make([]string, len(*e.Detail))
+	for i, item := range *e.Detail {
+		items[i] = item.String()
+	}
+	return fmt.Sprintf("validation error - %s", strings.Join(items, "; "))
+}
+
+// Fields returns the paths of the fields that failed validation, as returned by ValidationErrorDetailItem.Field,
+// in the order they were reported.
+func (e *ValidationError) Fields() []string {
+	if e.Detail == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(*e.Detail))
+	for _, item := range *e.Detail {
+		fields = append(fields, item.Field())
+	}
+	return fields
+}
+
+// StatusCode returns the HTTP status code of the response the error was returned with.
+func (e *ValidationError) StatusCode() int {
+	return e.HTTPStatus
+}
+
+// UnexpectedStatusError represents a response from the API with an unexpected HTTP status code, or an error
+// response whose body isn't in the expected format, e.g. an HTML page from a gateway or a truncated body.
+//
+// Detail is set if the response body contained error details in the same format as APIError. Otherwise, Body
+// holds the beginning of the response body, with its whitespace collapsed, to help identify where it came from.
+type UnexpectedStatusError struct {
+	HTTPStatus int
+	Detail     *APIErrorDetail
+	Body       string
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	msg := fmt.Sprintf("unexpected HTTP status %d %s", e.HTTPStatus, http.StatusText(e.HTTPStatus))
+	if e.Detail != nil && e.Detail.Message != "" {
+		msg += " - " + e.Detail.Message
+	} else if e.Body != "" {
+		msg += fmt.Sprintf(" - body: %q", e.Body)
+	}
+	return msg
+}
+
+// Is reports whether the error matches target, which is the case for ErrNotFound if the status is 404 and for
+// ErrQuotaExceeded if the details report that the character quota is used up, e.g. with a 402 or 403 status.
+func (e *UnexpectedStatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.HTTPStatus == http.StatusNotFound
+	case ErrQuotaExceeded:
+		return isQuotaExceeded(e.Detail)
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (e *UnexpectedStatusError) StatusCode() int {
+	return e.HTTPStatus
+}
+
+// newUnexpectedStatusError returns an UnexpectedStatusError for a response with the given status and body. The body
+// is only parsed on a best-effort basis, as it may not come from the API itself.
+func newUnexpectedStatusError(status int, body []byte) *UnexpectedStatusError {
+	statusErr := &UnexpectedStatusError{HTTPStatus: status}
+	var apiErr APIError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Detail != (APIErrorDetail{}) {
+		statusErr.Detail = &apiErr.Detail
+	} else {
+		statusErr.Body = bodySnippet(body)
+	}
+	return statusErr
+}
+
+// maxBodySnippet is the maximum number of characters of a response body kept in an UnexpectedStatusError.
+const maxBodySnippet = 200
+
+// bodySnippet returns the beginning of a response body for an UnexpectedStatusError, with its whitespace collapsed
+// and truncated to maxBodySnippet characters.
+func bodySnippet(body []byte) string {
+	s := []rune(strings.Join(strings.Fields(string(body)), " "))
+	if len(s) > maxBodySnippet {
+		return string(s[:maxBodySnippet]) + "…"
+	}
+	return string(s)
+}
+
+// NotReadyError is returned when the audio of a project is requested before its conversion is complete.
+// The request can be retried once the conversion has finished, which can be followed with GetProject.
+type NotReadyError struct {
+	Err error
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("conversion not ready: %s", e.Err)
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return e.Err
+}
+
+// notReady wraps err in a NotReadyError if the API rejected the request because a conversion is not complete,
+// which it reports with a 409 or 425 status.
+func notReady(err error) error {
+	var sc StatusCoder
+	if errors.As(err, &sc) && (sc.StatusCode() == http.StatusConflict || sc.StatusCode() == http.StatusTooEarly) {
+		return &NotReadyError{Err: err}
+	}
+	return err
+}
+
+// UnsupportedTagError is returned by ValidateText, and by the text-to-speech methods of clients using
+// SanitizeError, for text that contains tags the API doesn't interpret.
+type UnsupportedTagError struct {
+	// Tags are the unsupported tags, in the order they appear in the text.
+	Tags []string
+}
+
+func (e *UnsupportedTagError) Error() string {
+	return fmt.Sprintf("text contains unsupported tags: %s", strings.Join(e.Tags, ", "))
+}
+
+// multiError combines multiple errors, like the errors returned by errors.Join, which requires Go 1.20. It can
+// be inspected with errors.Is and errors.As, through Unwrap on Go 1.20+ and through its Is and As methods before.
+type multiError []error
+
+func (e multiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e multiError) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any of the errors matches target, for errors.Is on Go versions that don't support Unwrap
+// returning []error.
+func (e multiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target and sets target to it, for errors.As on Go versions that
+// don't support Unwrap returning []error.
+func (e multiError) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
 	}
-	return "validation error"
+	return false
 }
@@ -2,14 +2,16 @@ package elevenlabs_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 	"time"
 
-	"github.com/haguro/elevenlabs-go"
+	"github.com/clearlyip/elevenlabs-go"
 )
 
 func ExampleClient_TextToSpeech() {
@@ -19,7 +21,7 @@ func ExampleClient_TextToSpeech() {
 	// Create a TextToSpeechRequest
 	ttsReq := elevenlabs.TextToSpeechRequest{
 		Text:    "Hello, world! My name is Adam, nice to meet you!",
-		ModelID: "eleven_monolingual_v1",
+		ModelID: elevenlabs.ModelMonolingualV1,
 	}
 
 	// Call the TextToSpeech method on the client, using the "Adam"'s voice ID.
@@ -67,7 +69,7 @@ immediately, even if the buffer isn't full.`
 		"pNInz6obpgDQGcFmaJgB",
 		elevenlabs.TextToSpeechRequest{
 			Text:    message,
-			ModelID: "eleven_multilingual_v1",
+			ModelID: elevenlabs.ModelMultilingualV1,
 		}); err != nil {
 		log.Fatalf("Got %T error: %q\n", err, err)
 	}
@@ -113,12 +115,29 @@ immediately, even if the buffer isn't full.`
 		log.Fatal(err)
 	}
 
+	// Feed the message word by word through the text channel
+	textChan := make(chan string)
+	go func() {
+		defer close(textChan)
+		for _, word := range strings.Fields(message) {
+			textChan <- word + " "
+		}
+	}()
+
+	// Drain the non-audio responses (alignment info, final flag)
+	responseChan := make(chan elevenlabs.StreamingOutputResponse)
+	go func() {
+		for range responseChan {
+		}
+	}()
+
 	// Stream the audio to the pipe connected to mpv's standard input
 	if err := elevenlabs.TextToSpeechInputStream(
-		strings.NewReader(message),
+		textChan,
+		responseChan,
 		pipe,
 		"pNInz6obpgDQGcFmaJgB",
-		"eleven_multilingual_v1",
+		elevenlabs.ModelMultilingualV1,
 		elevenlabs.TextToSpeechInputStreamingRequest{
 			Text:                 " ",
 			TryTriggerGeneration: true,
@@ -144,6 +163,31 @@ immediately, even if the buffer isn't full.`
 	log.Print("All done.")
 }
 
+func ExampleNewClient_interrupt() {
+	// Cancel the client's context on Ctrl-C, which stops the stream below.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	client := elevenlabs.NewClient(ctx, "your-api-key", 30*time.Second)
+
+	f, err := os.Create("story.mp3")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	ttsReq := elevenlabs.TextToSpeechRequest{Text: "Once upon a time, in a land far, far away…"}
+	err = client.TextToSpeechStream(f, "pNInz6obpgDQGcFmaJgB", ttsReq)
+	if errors.Is(err, context.Canceled) {
+		// The audio received before the interruption was written to the file.
+		log.Print("Interrupted.")
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print("All done.")
+}
+
 func ExampleClient_GetHistory() {
 	// Define a helper function to print history items
 	printHistory := func(r elevenlabs.GetHistoryResponse, p int) {
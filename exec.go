@@ -0,0 +1,52 @@
+//go:build !elevenlabs_noexec
+
+package elevenlabs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TextToSpeechToCommand converts a given text to speech audio using a certain voice and streams the audio to the
+// standard input of a command, such as an audio player like ffplay or aplay:
+//
+//	err := client.TextToSpeechToCommand("ffplay", []string{"-nodisp", "-autoexit", "-"}, voiceID, ttsReq)
+//
+// It takes the name of the command and its arguments, followed by the same arguments as TextToSpeechStream. The
+// command is started before the request is sent and is killed if the client's context is done. Its standard
+// input is closed once the stream ends and the command is then waited for.
+//
+// It returns nil if both the stream and the command succeeded or an error otherwise. If the command fails, the
+// error wraps the *exec.ExitError and includes the end of the command's standard error output. This helper
+// can be excluded from builds with the elevenlabs_noexec build tag.
+func (c *Client) TextToSpeechToCommand(cmdName string, args []string, voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) error {
+	cmd := exec.CommandContext(c.ctx, cmdName, args...)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	streamErr := c.TextToSpeechStream(stdin, voiceID, ttsReq, queries...)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		// A stream error is most likely caused by the command exiting early, so the command's error is reported.
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 512 {
+			msg = "…" + msg[len(msg)-512:]
+		}
+		return fmt.Errorf("command %s failed: %w: %s", cmdName, err, msg)
+	}
+	return streamErr
+}
+
+// TextToSpeechToCommand calls the TextToSpeechToCommand method on the default client.
+func TextToSpeechToCommand(cmdName string, args []string, voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) error {
+	return getDefaultClient().TextToSpeechToCommand(cmdName, args, voiceID, ttsReq, queries...)
+}
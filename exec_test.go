@@ -0,0 +1,52 @@
+//go:build !elevenlabs_noexec
+
+package elevenlabs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clearlyip/elevenlabs-go"
+)
+
+func TestTextToSpeechToCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	server := testServer(t, testServerConfig{
+		expectedMethod:      http.MethodPost,
+		expectedContentType: contentTypeJSON,
+		statusCode:          http.StatusOK,
+		responseBody:        testRespBodies["TestTextToSpeechStream"],
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+	ttsReq := elevenlabs.TextToSpeechRequest{Text: "Test text"}
+
+	out := filepath.Join(t.TempDir(), "out.mp3")
+	if err := client.TextToSpeechToCommand("sh", []string{"-c", `cat > "$0"`, out}, "voiceID", ttsReq); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read command output: %s", err)
+	}
+	if string(got) != string(testRespBodies["TestTextToSpeechStream"]) {
+		t.Errorf("Expected command to receive %q, got %q", testRespBodies["TestTextToSpeechStream"], got)
+	}
+
+	err = client.TextToSpeechToCommand("sh", []string{"-c", "echo unsupported format >&2; exit 3"}, "voiceID", ttsReq)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Expected an exit error with code 3, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("Expected error to include the command's output, got %q", err)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build go1.23
+
+package elevenlabs
+
+import "iter"
+
+// HistoryItems returns an iterator over the history items of all created audio, fetching the following
+// pages as needed.
+//
+// It accepts the same optional list of QueryFunc 'queries' as GetHistory, which are applied to every page.
+//
+// The iterator yields the items one at a time along with a nil error. If a page fails to be retrieved, the
+// error is yielded with a zero HistoryItem and the iteration stops. No further pages are requested once the
+// consumer stops the iteration.
+func (c *Client) HistoryItems(queries ...QueryFunc) iter.Seq2[HistoryItem, error] {
+	return func(yield func(HistoryItem, error) bool) {
+		resp, nextPage, err := c.GetHistory(queries...)
+		for {
+			if err != nil {
+				yield(HistoryItem{}, err)
+				return
+			}
+			for _, item := range resp.History {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if nextPage == nil {
+				return
+			}
+			resp, nextPage, err = nextPage()
+		}
+	}
+}
+
+// HistoryItems calls the HistoryItems method on the default client.
+func HistoryItems(queries ...QueryFunc) iter.Seq2[HistoryItem, error] {
+	return getDefaultClient().HistoryItems(queries...)
+}
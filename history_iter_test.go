@@ -0,0 +1,116 @@
+//go:build go1.23
+
+package elevenlabs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/clearlyip/elevenlabs-go"
+)
+
+// historyPagesServer serves the given pages of history items, each page but the last one reporting that
+// more items are available. It sends the start_after_history_item_id query of every request to the
+// returned channel.
+func historyPagesServer(t *testing.T, pages [][]string) (*httptest.Server, chan string) {
+	t.Helper()
+	startAfterCh := make(chan string, len(pages)+1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startAfter := r.URL.Query().Get("start_after_history_item_id")
+		startAfterCh <- startAfter
+		page := 0
+		if startAfter != "" {
+			for i, p := range pages {
+				if p[len(p)-1] == startAfter {
+					page = i + 1
+				}
+			}
+		}
+		if page >= len(pages) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp := elevenlabs.GetHistoryResponse{
+			LastHistoryItemId: pages[page][len(pages[page])-1],
+			HasMore:           page < len(pages)-1,
+		}
+		for _, id := range pages[page] {
+			resp.History = append(resp.History, elevenlabs.HistoryItem{HistoryItemId: id})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return server, startAfterCh
+}
+
+func TestHistoryItems(t *testing.T) {
+	server, startAfterCh := historyPagesServer(t, [][]string{{"item1", "item2"}, {"item3", "item4"}, {"item5"}})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	var ids []string
+	for item, err := range client.HistoryItems(elevenlabs.PageSize(2)) {
+		if err != nil {
+			t.Fatalf("Expected no errors, got error: %q", err)
+		}
+		ids = append(ids, item.HistoryItemId)
+		if len(ids) > 5 {
+			t.Fatalf("Expected the iteration to end after the last page, got items %q", ids)
+		}
+	}
+	if expIds := []string{"item1", "item2", "item3", "item4", "item5"}; !reflect.DeepEqual(expIds, ids) {
+		t.Errorf("Expected items %q, got %q", expIds, ids)
+	}
+	close(startAfterCh)
+	var requests []string
+	for startAfter := range startAfterCh {
+		requests = append(requests, startAfter)
+	}
+	if expRequests := []string{"", "item2", "item4"}; !reflect.DeepEqual(expRequests, requests) {
+		t.Errorf("Expected pages to be requested after %q, got %q", expRequests, requests)
+	}
+}
+
+func TestHistoryItemsBreak(t *testing.T) {
+	server, startAfterCh := historyPagesServer(t, [][]string{{"item1", "item2"}, {"item3"}})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	for item, err := range client.HistoryItems() {
+		if err != nil {
+			t.Fatalf("Expected no errors, got error: %q", err)
+		}
+		if item.HistoryItemId == "item2" {
+			break
+		}
+	}
+	if n := len(startAfterCh); n != 1 {
+		t.Errorf("Expected a single page to be requested, got %d", n)
+	}
+}
+
+func TestHistoryItemsError(t *testing.T) {
+	server := testServer(t, testServerConfig{
+		expectedMethod: http.MethodGet,
+		statusCode:     http.StatusServiceUnavailable,
+	})
+	defer server.Close()
+	client := elevenlabs.NewMockClient(context.Background(), server.URL, mockAPIKey, mockTimeout)
+
+	var n int
+	for _, err := range client.HistoryItems() {
+		n++
+		var statusErr *elevenlabs.UnexpectedStatusError
+		if !errors.As(err, &statusErr) {
+			t.Errorf("Expected error of type *UnexpectedStatusError, got %T: %v", err, err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("Expected a single iteration, got %d", n)
+	}
+}
@@ -0,0 +1,129 @@
+package elevenlabs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeMode represents the ways text can be checked for unsupported markup before it is sent to the API.
+// See WithTextSanitizing.
+type SanitizeMode int
+
+const (
+	// SanitizeStrip removes unsupported tags from the text, as SanitizeText does.
+	SanitizeStrip SanitizeMode = iota + 1
+	// SanitizeError rejects text with unsupported tags, as ValidateText does.
+	SanitizeError
+)
+
+// supportedTags are the inline tags the API interprets. Any other tag would be read out literally.
+var supportedTags = map[string]bool{"break": true, "phoneme": true}
+
+// tagPattern matches opening, closing and self-closing tags, capturing their name.
+var tagPattern = regexp.MustCompile(`</?([A-Za-z][\w:.-]*)(?:\s[^<>]*)?/?>`)
+
+// SanitizeText removes the tags the API doesn't interpret from text, keeping the text they enclose. Without
+// it, SSML such as <speak>, <prosody> or <emphasis> ends up being read out as is.
+//
+// The API supports the following tags, which are kept:
+//
+//	<break time="1.5s" />
+//
+// pauses for the given duration, of up to 3 seconds, and
+//
+//	<phoneme alphabet="cmu-arpabet" ph="M AE1 D IH0 S AH0 N">Madison</phoneme>
+//
+// sets the pronunciation of a word, with the "ipa" or "cmu-arpabet" alphabets, on the models that support it.
+func SanitizeText(text string) string {
+	return tagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		if supportedTags[strings.ToLower(tagPattern.FindStringSubmatch(tag)[1])] {
+			return tag
+		}
+		return ""
+	})
+}
+
+// ValidateText checks that text contains no tags the API doesn't interpret (see SanitizeText).
+//
+// It returns nil if it doesn't, or an *UnsupportedTagError otherwise.
+func ValidateText(text string) error {
+	var tags []string
+	for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+		if !supportedTags[strings.ToLower(m[1])] {
+			tags = append(tags, m[0])
+		}
+	}
+	if len(tags) > 0 {
+		return &UnsupportedTagError{Tags: tags}
+	}
+	return nil
+}
+
+// Break returns a break tag that makes the API pause for the given duration, e.g. <break time="1.5s" />, to be
+// composed into the text of a request:
+//
+//	ttsReq.Text = "Welcome." + elevenlabs.Break(time.Second) + "Please hold."
+//
+// The API supports pauses of up to 3 seconds. Negative durations are treated as zero. Break tags are kept by
+// SanitizeText.
+func Break(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return fmt.Sprintf(`<break time="%ss" />`, strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+}
+
+// TextBuilder assembles text with pauses for the text of a request, separating the parts with spaces:
+//
+//	text := new(elevenlabs.TextBuilder).Text("Chapter one.").Pause(2 * time.Second).Text("It was a dark night.").String()
+//
+// The zero value is an empty builder ready to use.
+type TextBuilder struct {
+	b strings.Builder
+}
+
+// Text appends s to the text.
+func (t *TextBuilder) Text(s string) *TextBuilder {
+	t.write(s)
+	return t
+}
+
+// Pause appends a break tag for the given duration to the text, see Break.
+func (t *TextBuilder) Pause(d time.Duration) *TextBuilder {
+	t.write(Break(d))
+	return t
+}
+
+// String returns the assembled text.
+func (t *TextBuilder) String() string {
+	return t.b.String()
+}
+
+// write appends s, preceded by a space unless the text is empty or there's already whitespace in between.
+func (t *TextBuilder) write(s string) {
+	if s == "" {
+		return
+	}
+	last, _ := utf8.DecodeLastRuneInString(t.b.String())
+	first, _ := utf8.DecodeRuneInString(s)
+	if t.b.Len() > 0 && !unicode.IsSpace(last) && !unicode.IsSpace(first) {
+		t.b.WriteByte(' ')
+	}
+	t.b.WriteString(s)
+}
+
+// sanitize applies the SanitizeMode set with WithTextSanitizing to text.
+func (c *Client) sanitize(text string) (string, error) {
+	switch c.sanitizeMode {
+	case SanitizeStrip:
+		return SanitizeText(text), nil
+	case SanitizeError:
+		return text, ValidateText(text)
+	}
+	return text, nil
+}
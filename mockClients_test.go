@@ -2,6 +2,7 @@ package elevenlabs
 
 import (
 	"context"
+	"reflect"
 	"time"
 )
 
@@ -16,3 +17,19 @@ func MockDefaultClient(baseURL string) *Client {
 	defaultClient.baseURL = baseURL
 	return defaultClient
 }
+
+func NewMockWSClient(ctx context.Context, baseWSURL, apiKey string, reqTimeout time.Duration) *Client {
+	c := NewClient(ctx, apiKey, reqTimeout)
+	c.baseWSUrl = baseWSURL
+	return c
+}
+
+// EqualClients reports whether two clients are deeply equal, ignoring their cancel functions and transports,
+// which are never deeply equal.
+func EqualClients(a, b *Client) bool {
+	ac, bc := *a, *b
+	ac.cancel, bc.cancel = nil, nil
+	ac.httpClient, bc.httpClient = nil, nil
+	ac.transport, bc.transport = nil, nil
+	return reflect.DeepEqual(ac, bc)
+}
@@ -3,13 +3,63 @@ package elevenlabs
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// Model IDs of the models available through the API. They are plain strings, so model IDs
+// that are not listed here can still be used.
+const (
+	ModelMultilingualV2    = "eleven_multilingual_v2"
+	ModelMultilingualV1    = "eleven_multilingual_v1"
+	ModelMonolingualV1     = "eleven_monolingual_v1"
+	ModelTurboV2           = "eleven_turbo_v2"
+	ModelTurboV2_5         = "eleven_turbo_v2_5"
+	ModelFlashV2           = "eleven_flash_v2"
+	ModelFlashV2_5         = "eleven_flash_v2_5"
+	ModelEnglishSTSV2      = "eleven_english_sts_v2"
+	ModelMultilingualSTSV2 = "eleven_multilingual_sts_v2"
+)
+
+// DefaultModel is the model the API uses for text-to-speech requests that don't set a model ID. Clients
+// configured with WithModelValidation set it explicitly.
+const DefaultModel = ModelMultilingualV2
+
+// textToSpeechModels are the models known to this package that can convert text to speech.
+var textToSpeechModels = map[string]bool{
+	ModelMultilingualV2: true, ModelMultilingualV1: true, ModelMonolingualV1: true,
+	ModelTurboV2: true, ModelTurboV2_5: true, ModelFlashV2: true, ModelFlashV2_5: true,
+}
+
+// speechToSpeechModels are the models known to this package that can only convert speech.
+var speechToSpeechModels = map[string]bool{ModelEnglishSTSV2: true, ModelMultilingualSTSV2: true}
+
+// ValidateTextToSpeechModel checks that id is one of the text-to-speech models known to this package (see the
+// Model constants). Speech-to-speech models, such as ModelEnglishSTSV2, are rejected, as is an empty ID.
+//
+// It returns nil if the model is known or an error otherwise. Unknown models can still be used without
+// validation, e.g. those added to the API after this package was released.
+func ValidateTextToSpeechModel(id string) error {
+	switch {
+	case id == "":
+		return errors.New("missing model ID")
+	case speechToSpeechModels[id]:
+		return fmt.Errorf("model %q is a speech-to-speech model and can't convert text", id)
+	case !textToSpeechModels[id]:
+		return fmt.Errorf("unknown text-to-speech model %q", id)
+	}
+	return nil
+}
+
 type Language struct {
 	LanguageId string `json:"language_id"`
 	Name       string `json:"name"`
@@ -21,10 +71,12 @@ type Model struct {
 	CanDoVoiceConversion               bool       `json:"can_do_voice_conversion"`
 	CanUseSpeakerBoost                 bool       `json:"can_use_speaker_boost"`
 	CanUseStyle                        bool       `json:"can_use_style"`
+	ConcurrencyGroup                   string     `json:"concurrency_group"`
 	Description                        string     `json:"description"`
 	Languages                          []Language `json:"languages"`
 	MaxCharactersRequestFreeUser       int        `json:"max_characters_request_free_user"`
 	MaxCharactersRequestSubscribedUser int        `json:"max_characters_request_subscribed_user"`
+	MaximumTextLengthPerRequest        int        `json:"maximum_text_length_per_request"`
 	ModelId                            string     `json:"model_id"`
 	Name                               string     `json:"name"`
 	RequiresAlphaAccess                bool       `json:"requires_alpha_access"`
@@ -32,16 +84,72 @@ type Model struct {
 	TokenCostFactor                    float32    `json:"token_cost_factor"`
 }
 
+// CanFineTune reports whether voices can be fine-tuned for the model, as reported by the API in
+// CanBeFineTuned. Models with RequiresAlphaAccess set may additionally require access to be granted.
+func (m Model) CanFineTune() bool {
+	return m.CanBeFineTuned
+}
+
+// SupportsLanguage reports whether the model supports the language with the given code (e.g. "en").
+// The code is matched case-insensitively.
+func (m Model) SupportsLanguage(code string) bool {
+	for _, l := range m.Languages {
+		if strings.EqualFold(l.LanguageId, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxChars returns the maximum number of characters of the text of a single request to the model, for free
+// or subscribed users. If the API didn't report a limit for the given kind of user, the general
+// MaximumTextLengthPerRequest is returned, which is 0 if unknown as well.
+func (m Model) MaxChars(subscribed bool) int {
+	limit := m.MaxCharactersRequestFreeUser
+	if subscribed {
+		limit = m.MaxCharactersRequestSubscribedUser
+	}
+	if limit > 0 {
+		return limit
+	}
+	return m.MaximumTextLengthPerRequest
+}
+
 type TextToSpeechRequest struct {
-	Text          string         `json:"text"`
-	ModelID       string         `json:"model_id,omitempty"`
-	VoiceSettings *VoiceSettings `json:"voice_settings,omitempty"`
+	Text               string         `json:"text"`
+	ModelID            string         `json:"model_id,omitempty"`
+	LanguageCode       string         `json:"language_code,omitempty"`
+	VoiceSettings      *VoiceSettings `json:"voice_settings,omitempty"`
+	PreviousText       string         `json:"previous_text,omitempty"`
+	NextText           string         `json:"next_text,omitempty"`
+	PreviousRequestIds []string       `json:"previous_request_ids,omitempty"`
+	NextRequestIds     []string       `json:"next_request_ids,omitempty"`
+}
+
+// WithModel returns a copy of the request that uses the model with the given ID, e.g. ModelTurboV2_5:
+//
+//	ttsReq := elevenlabs.TextToSpeechRequest{Text: text}.WithModel(elevenlabs.ModelTurboV2_5)
+func (r TextToSpeechRequest) WithModel(id string) TextToSpeechRequest {
+	r.ModelID = id
+	return r
+}
+
+// CharacterCost returns the number of characters the request is expected to be billed for: the number of
+// characters (runes) of Text, which is what the API counts, including spaces and tags such as breaks. Text
+// normalization happens after billing, so numbers or dates that are spelled out don't cost more.
+//
+// It is meant to estimate the quota usage before sending a request, see Subscription.RemainingCharacters. The
+// character count reported by the API is authoritative, as the billing of some models or plans may differ.
+func (r TextToSpeechRequest) CharacterCost() int {
+	return utf8.RuneCountInString(r.Text)
 }
 
 type GenerationConfig struct {
 	ChunkLengthSchedule []int `json:"chunk_length_schedule"`
 }
 
+// TextToSpeechInputStreamingRequest is the initial message of a stream-input session. VoiceSettings and
+// GenerationConfig, when set, apply to the whole session, including sessions re-established after a reconnect.
 type TextToSpeechInputStreamingRequest struct {
 	Text                 string            `json:"text"`
 	TryTriggerGeneration bool              `json:"try_trigger_generation"`
@@ -51,10 +159,66 @@ type TextToSpeechInputStreamingRequest struct {
 
 type GetVoicesResponse struct {
 	Voices []Voice `json:"voices"`
+	// HasMore and NextPageToken are only set by GetVoicesPaged, when the API paginates the voices.
+	HasMore       bool   `json:"has_more"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// SharedVoice is a voice shared by another user in the voice library, as returned by GetSharedVoices. It can be
+// added to the account with its PublicOwnerId and VoiceId.
+type SharedVoice struct {
+	PublicOwnerId         string `json:"public_owner_id"`
+	VoiceId               string `json:"voice_id"`
+	Name                  string `json:"name"`
+	Accent                string `json:"accent"`
+	Gender                string `json:"gender"`
+	Age                   string `json:"age"`
+	Descriptive           string `json:"descriptive"`
+	UseCase               string `json:"use_case"`
+	Category              string `json:"category"`
+	Language              string `json:"language"`
+	Locale                string `json:"locale"`
+	Description           string `json:"description"`
+	PreviewUrl            string `json:"preview_url"`
+	DateUnix              int    `json:"date_unix"`
+	ClonedByCount         int    `json:"cloned_by_count"`
+	UsageCharacterCount1y int    `json:"usage_character_count_1y"`
+	FreeUsersAllowed      bool   `json:"free_users_allowed"`
+	Featured              bool   `json:"featured"`
 }
 
+type GetSharedVoicesResponse struct {
+	Voices  []SharedVoice `json:"voices"`
+	HasMore bool          `json:"has_more"`
+}
+
+// SharedVoicesByLanguage groups shared voices by language, e.g. for the first level of a voice picker. The
+// voices of each language are in the same order as in voices. Voices without a language are grouped under "".
+func SharedVoicesByLanguage(voices []SharedVoice) map[string][]SharedVoice {
+	return groupSharedVoices(voices, func(v SharedVoice) string { return v.Language })
+}
+
+// SharedVoicesByCategory groups shared voices by category, e.g. "professional" or "high_quality", in the same
+// way as SharedVoicesByLanguage. Both can be combined to build a tree of voices grouped by language, then by
+// category.
+func SharedVoicesByCategory(voices []SharedVoice) map[string][]SharedVoice {
+	return groupSharedVoices(voices, func(v SharedVoice) string { return v.Category })
+}
+
+func groupSharedVoices(voices []SharedVoice, key func(SharedVoice) string) map[string][]SharedVoice {
+	groups := make(map[string][]SharedVoice)
+	for _, v := range voices {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// AddVoiceResponse is the response of the API when adding a voice. If RequiresVerification is true, the voice
+// can't be used until it has been verified, e.g. by completing a captcha on the website.
 type AddVoiceResponse struct {
-	VoiceId string `json:"voice_id"`
+	VoiceId              string `json:"voice_id"`
+	RequiresVerification bool   `json:"requires_verification"`
 }
 
 type Voice struct {
@@ -72,11 +236,66 @@ type Voice struct {
 	VoiceId                 string            `json:"voice_id"`
 }
 
+// Accent returns the "accent" label of the voice, e.g. "american", or an empty string if it isn't set.
+func (v Voice) Accent() string {
+	return v.Labels["accent"]
+}
+
+// Gender returns the "gender" label of the voice, e.g. "female", or an empty string if it isn't set.
+func (v Voice) Gender() string {
+	return v.Labels["gender"]
+}
+
+// Age returns the "age" label of the voice, e.g. "young", or an empty string if it isn't set.
+func (v Voice) Age() string {
+	return v.Labels["age"]
+}
+
+// UseCase returns the "use_case" label of the voice, e.g. "narration", or an empty string if it isn't set.
+func (v Voice) UseCase() string {
+	return v.Labels["use_case"]
+}
+
+// VoiceSettings are the settings of a voice, which can also be overridden per request.
+//
+// The fields are pointers, so that a zero value, such as a stability of 0 or a disabled speaker boost, can be
+// told apart from a setting that was left unset. Unset settings are omitted from requests, so that the API
+// applies the voice's own settings. Use NewVoiceSettings, or Float32 and Bool, to set them:
+//
+//	settings := elevenlabs.NewVoiceSettings(0, 0.75).WithSpeakerBoost(false)
 type VoiceSettings struct {
-	SimilarityBoost float32 `json:"similarity_boost"`
-	Stability       float32 `json:"stability"`
-	Style           float32 `json:"style,omitempty"`
-	SpeakerBoost    bool    `json:"use_speaker_boost,omitempty"`
+	SimilarityBoost *float32 `json:"similarity_boost,omitempty"`
+	Stability       *float32 `json:"stability,omitempty"`
+	Style           *float32 `json:"style,omitempty"`
+	SpeakerBoost    *bool    `json:"use_speaker_boost,omitempty"`
+}
+
+// NewVoiceSettings returns VoiceSettings with the given stability and similarity boost, and the other
+// settings unset.
+func NewVoiceSettings(stability, similarityBoost float32) VoiceSettings {
+	return VoiceSettings{Stability: Float32(stability), SimilarityBoost: Float32(similarityBoost)}
+}
+
+// WithStyle returns a copy of the settings with the given style exaggeration.
+func (s VoiceSettings) WithStyle(style float32) VoiceSettings {
+	s.Style = Float32(style)
+	return s
+}
+
+// WithSpeakerBoost returns a copy of the settings with speaker boost enabled or disabled.
+func (s VoiceSettings) WithSpeakerBoost(enabled bool) VoiceSettings {
+	s.SpeakerBoost = Bool(enabled)
+	return s
+}
+
+// Float32 returns a pointer to v, for setting the fields of VoiceSettings.
+func Float32(v float32) *float32 {
+	return &v
+}
+
+// Bool returns a pointer to v, for setting the fields of VoiceSettings such as SpeakerBoost.
+func Bool(v bool) *bool {
+	return &v
 }
 
 type VoiceSharing struct {
@@ -103,6 +322,8 @@ type VoiceSharing struct {
 	WhitelistedEmails      []string          `json:"whitelisted_emails"`
 }
 
+// VoiceSample describes an audio sample of a voice, as returned by GetVoiceSamples. Its audio can be downloaded
+// with GetSampleAudio.
 type VoiceSample struct {
 	FileName  string `json:"file_name"`
 	Hash      string `json:"hash"`
@@ -111,6 +332,9 @@ type VoiceSample struct {
 	SizeBytes int    `json:"size_bytes"`
 }
 
+// FineTuning describes the fine-tuning of a professional voice clone. State, Progress and Message are keyed by
+// the ID of the model the voice is fine-tuned for. The states are "not_started", "queued", "fine_tuning",
+// "fine_tuned", "failed" and "delayed", and the progress goes from 0 to 1.
 type FineTuning struct {
 	FineTuningRequested         bool                  `json:"fine_tuning_requested"`
 	FineTuningState             string                `json:"finetuning_state"`
@@ -122,8 +346,15 @@ type FineTuning struct {
 	VerificationAttempts        []VerificationAttempt `json:"verification_attempts"`
 	VerificationAttemptsCount   int                   `json:"verification_attempts_count"`
 	VerificationFailures        []string              `json:"verification_failures"`
+	State                       map[string]string     `json:"state"`
+	Progress                    map[string]float64    `json:"progress"`
+	Message                     map[string]string     `json:"message"`
 }
 
+// FineTuningStatus is the fine-tuning status of a voice, as returned by GetVoiceFineTuningStatus. It is the
+// FineTuning of the voice.
+type FineTuningStatus = FineTuning
+
 type ManualVerification struct {
 	ExtraText       string `json:"extra_text"`
 	Files           []File `json:"files"`
@@ -155,16 +386,51 @@ type Recording struct {
 	UploadDateUnix int    `json:"upload_date_unix"`
 }
 
+// DownloadHistoryRequest specifies the history items to download with DownloadHistoryAudio.
+//
+// OutputFormat can be set to "wav" to have the audio transcoded; it is left to the server default
+// (mpeg) when empty.
 type DownloadHistoryRequest struct {
 	HistoryItemIds []string `json:"history_item_ids"`
+	OutputFormat   string   `json:"output_format,omitempty"`
 }
 
+// IsZip reports whether the download for this request is a zip archive, which is the case when more
+// than one history item is requested. Otherwise, the download is a single audio file.
+func (r DownloadHistoryRequest) IsZip() bool {
+	return len(r.HistoryItemIds) > 1
+}
+
+// GetHistoryResponse is a page of history items.
+//
+// The API doesn't report the total number of history items, only whether more pages follow, so the total
+// number of pages is only known once the last page is retrieved. EstimatedPages gives a lower bound before then.
 type GetHistoryResponse struct {
 	History           []HistoryItem `json:"history"`
 	LastHistoryItemId string        `json:"last_history_item_id"`
 	HasMore           bool          `json:"has_more"`
 }
 
+// NumItems returns the number of history items in the page.
+func (r GetHistoryResponse) NumItems() int {
+	return len(r.History)
+}
+
+// EstimatedPages returns the minimum number of pages of history, given that this page is the page-th one,
+// starting at 1, of pages requested with the same page size (see PageSize).
+//
+// The returned number is exact if no more pages follow. Otherwise, only the next page is known to exist, so it
+// is page+1. It returns 0 if page is not positive.
+func (r GetHistoryResponse) EstimatedPages(page int) int {
+	if page <= 0 {
+		return 0
+	}
+	if !r.HasMore {
+		return page
+	}
+	return page + 1
+}
+
 type HistoryItem struct {
 	CharacterCountChangeFrom int           `json:"character_count_change_from"`
 	CharacterCountChangeTo   int           `json:"character_count_change_to"`
@@ -205,6 +471,7 @@ type Subscription struct {
 	Currency                       string  `json:"currency"`
 	NextCharacterCountResetUnix    int     `json:"next_character_count_reset_unix"`
 	VoiceLimit                     int     `json:"voice_limit"`
+	VoiceSlotsUsed                 int     `json:"voice_slots_used"`
 	ProfessionalVoiceLimit         int     `json:"professional_voice_limit"`
 	Status                         string  `json:"status"`
 	Tier                           string  `json:"tier"`
@@ -215,6 +482,105 @@ type Subscription struct {
 	withInvoicingDetails           bool
 }
 
+// RemainingVoiceSlots returns the number of voices that can still be added before the subscription's voice
+// limit is reached. AddVoice fails once it is 0.
+func (s Subscription) RemainingVoiceSlots() int {
+	if remaining := s.VoiceLimit - s.VoiceSlotsUsed; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// RemainingCharacters returns the number of characters that can still be converted before the subscription's
+// character limit is reached, until it is reset. Requests are rejected with ErrQuotaExceeded once it is 0, unless
+// the limit can be extended.
+func (s Subscription) RemainingCharacters() int {
+	if remaining := s.CharacterLimit - s.CharacterCount; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Subscription tiers, as returned by Subscription.NormalizedTier, from the lowest to the highest.
+const (
+	TierFree       = "free"
+	TierStarter    = "starter"
+	TierCreator    = "creator"
+	TierPro        = "pro"
+	TierScale      = "scale"
+	TierBusiness   = "business"
+	TierEnterprise = "enterprise"
+)
+
+// tierRanks orders the known tiers.
+var tierRanks = map[string]int{
+	TierFree:       1,
+	TierStarter:    2,
+	TierCreator:    3,
+	TierPro:        4,
+	TierScale:      5,
+	TierBusiness:   6,
+	TierEnterprise: 7,
+}
+
+// tierCharacterLimits are the monthly character limits of the tiers, used to infer the tier of
+// subscriptions whose tier name is not known.
+var tierCharacterLimits = []struct {
+	tier  string
+	limit int
+}{
+	{TierBusiness, 11000000},
+	{TierScale, 2000000},
+	{TierPro, 500000},
+	{TierCreator, 100000},
+	{TierStarter, 30000},
+	{TierFree, 0},
+}
+
+// NormalizedTier returns the tier of the subscription as one of the Tier constants.
+//
+// Tier names are matched case-insensitively, ignoring billing suffixes such as "_annual", and former
+// names such as "independent_publisher" are mapped to their current tier. If the tier name is not known,
+// the tier is inferred from the subscription's character limit.
+func (s Subscription) NormalizedTier() string {
+	tier := strings.ToLower(strings.TrimSpace(s.Tier))
+	for _, suffix := range []string{"_annual", "_monthly", "_new"} {
+		tier = strings.TrimSuffix(tier, suffix)
+	}
+	switch tier {
+	case "independent_publisher":
+		return TierPro
+	case "growing_business":
+		return TierScale
+	}
+	if _, ok := tierRanks[tier]; ok {
+		return tier
+	}
+	for _, t := range tierCharacterLimits {
+		if s.CharacterLimit >= t.limit {
+			return t.tier
+		}
+	}
+	return TierFree
+}
+
+// atLeast reports whether the subscription's tier is the given tier or a higher one.
+func (s Subscription) atLeast(tier string) bool {
+	return tierRanks[s.NormalizedTier()] >= tierRanks[tier]
+}
+
+// CanUse192kbps reports whether the subscription allows the 192kbps mp3 output format (mp3_44100_192),
+// which requires the Creator tier or above.
+func (s Subscription) CanUse192kbps() bool {
+	return s.atLeast(TierCreator)
+}
+
+// CanUsePCM44100 reports whether the subscription allows the 44.1kHz PCM output format (pcm_44100),
+// which requires the Pro tier or above.
+func (s Subscription) CanUsePCM44100() bool {
+	return s.atLeast(TierPro)
+}
+
 type Invoice struct {
 	AmountDueCents         int `json:"amount_due_cents"`
 	NextPaymentAttemptUnix int `json:"next_payment_attempt_unix"`
@@ -229,11 +595,54 @@ type User struct {
 	CanUseDelayedPaymentMethods bool         `json:"can_use_delayed_payment_methods"`
 }
 
+// UsageStats is the character usage of the user over time, as returned by GetCharacterUsage.
+//
+// Time holds the start of each time bucket as a Unix timestamp in milliseconds. Usage maps each breakdown
+// category, e.g. "All" or a voice ID, to the number of characters used in each bucket, in the same order as Time.
+type UsageStats struct {
+	Time  []int64              `json:"time"`
+	Usage map[string][]float64 `json:"usage"`
+}
+
+// Timestamps returns the start of each time bucket as a time.Time.
+func (s UsageStats) Timestamps() []time.Time {
+	ts := make([]time.Time, len(s.Time))
+	for i, ms := range s.Time {
+		ts[i] = time.UnixMilli(ms)
+	}
+	return ts
+}
+
+// Total returns the total number of characters used in the given breakdown category over all time buckets.
+func (s UsageStats) Total(category string) float64 {
+	var total float64
+	for _, n := range s.Usage[category] {
+		total += n
+	}
+	return total
+}
+
+// AddEditVoiceRequest contains the information of a voice to be added with AddVoice or edited with EditVoice.
+//
+// It is sent as a multipart form, in which Labels are encoded as a single JSON string field.
 type AddEditVoiceRequest struct {
 	Name        string
 	FilePaths   []string
 	Description string
 	Labels      map[string]string
+	// RemoveBackgroundNoise asks the API to remove background noise from the uploaded samples.
+	RemoveBackgroundNoise bool
+	// Samples are uploaded along with the files at FilePaths, with an explicit content type.
+	Samples []SampleFile
+}
+
+// SampleFile is an audio file to be uploaded with AddVoice or EditVoice.
+type SampleFile struct {
+	// Path is the path to the audio file.
+	Path string
+	// ContentType is the MIME type of the audio file, e.g. "audio/mpeg" or "audio/wav". Files without one
+	// are uploaded as "application/octet-stream", leaving the API to detect their format.
+	ContentType string
 }
 
 func (r *AddEditVoiceRequest) buildRequestBody() (*bytes.Buffer, string, error) {
@@ -261,24 +670,245 @@ func (r *AddEditVoiceRequest) buildRequestBody() (*bytes.Buffer, string, error)
 		}
 	}
 
+	if err := w.WriteField("remove_background_noise", strconv.FormatBool(r.RemoveBackgroundNoise)); err != nil {
+		return buildFailed(err)
+	}
+
 	for _, file := range r.FilePaths {
-		f, err := os.Open(file)
-		if err != nil {
+		if err := writeFormFile(w, "files", file, ""); err != nil {
 			return buildFailed(err)
 		}
-		defer f.Close()
+	}
+	for _, sample := range r.Samples {
+		if err := writeFormFile(w, "files", sample.Path, sample.ContentType); err != nil {
+			return buildFailed(err)
+		}
+	}
 
-		fw, err := w.CreateFormFile("files", filepath.Base(file))
+	err := w.Close()
+	if err != nil {
+		return buildFailed(err)
+	}
+
+	return &b, w.FormDataContentType(), nil
+}
+
+// buildVoiceMetadataBody builds the multipart body of a voice edit request that only contains the given metadata.
+// The name is always sent, as the API requires it, while an empty description and nil labels are left out.
+func buildVoiceMetadataBody(name, description string, labels map[string]string) (*bytes.Buffer, string, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	buildFailed := func(err error) (*bytes.Buffer, string, error) {
+		return nil, "", fmt.Errorf("failed to build request body: %w", err)
+	}
+
+	if err := w.WriteField("name", name); err != nil {
+		return buildFailed(err)
+	}
+	if description != "" {
+		if err := w.WriteField("description", description); err != nil {
+			return buildFailed(err)
+		}
+	}
+	if labels != nil {
+		labelsJson, err := json.Marshal(labels)
 		if err != nil {
 			return buildFailed(err)
 		}
-		if _, err = io.Copy(fw, f); err != nil {
+		if err := w.WriteField("labels", string(labelsJson)); err != nil {
 			return buildFailed(err)
 		}
 	}
 
-	err := w.Close()
+	if err := w.Close(); err != nil {
+		return buildFailed(err)
+	}
+	return &b, w.FormDataContentType(), nil
+}
+
+// quoteEscaper escapes the quoted parameters of a Content-Disposition header, as multipart.Writer does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeFormFile writes the content of the file at path to a new form file field of w. The part's Content-Type
+// header is set to contentType, or "application/octet-stream" if it's empty.
+func writeFormFile(w *multipart.Writer, fieldName, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFormFileContent(w, fieldName, filepath.Base(path), contentType, f)
+}
+
+// writeFormFileContent writes the content read from r to a new form file field of w, like writeFormFile.
+func writeFormFileContent(w *multipart.Writer, fieldName, fileName, contentType string, r io.Reader) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	h.Set("Content-Type", contentType)
+	fw, err := w.CreatePart(h)
 	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+// buildSampleUploadBody builds the multipart body of a voice edit request that adds the given audio as a sample,
+// along with the name of the voice, which the API requires. The other metadata of the voice is left unchanged.
+func buildSampleUploadBody(name, fileName, contentType string, audio []byte) (*bytes.Buffer, string, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	buildFailed := func(err error) (*bytes.Buffer, string, error) {
+		return nil, "", fmt.Errorf("failed to build request body: %w", err)
+	}
+
+	if err := w.WriteField("name", name); err != nil {
+		return buildFailed(err)
+	}
+	if err := writeFormFileContent(w, "files", fileName, contentType, bytes.NewReader(audio)); err != nil {
+		return buildFailed(err)
+	}
+	if err := w.Close(); err != nil {
+		return buildFailed(err)
+	}
+	return &b, w.FormDataContentType(), nil
+}
+
+// Project conversion states, as reported by the State field of Project.
+const (
+	ProjectStateDefault    = "default"
+	ProjectStateConverting = "converting"
+	ProjectStateInQueue    = "in_queue"
+)
+
+type GetProjectsResponse struct {
+	Projects []Project `json:"projects"`
+}
+
+type AddProjectResponse struct {
+	Project Project `json:"project"`
+}
+
+// Project is a long-form Studio project. Chapters are only populated by GetProject.
+type Project struct {
+	ProjectId               string    `json:"project_id"`
+	Name                    string    `json:"name"`
+	CreateDateUnix          int       `json:"create_date_unix"`
+	DefaultTitleVoiceId     string    `json:"default_title_voice_id"`
+	DefaultParagraphVoiceId string    `json:"default_paragraph_voice_id"`
+	DefaultModelId          string    `json:"default_model_id"`
+	LastConversionDateUnix  int       `json:"last_conversion_date_unix"`
+	CanBeDownloaded         bool      `json:"can_be_downloaded"`
+	Title                   string    `json:"title"`
+	Author                  string    `json:"author"`
+	IsbnNumber              string    `json:"isbn_number"`
+	VolumeNormalization     bool      `json:"volume_normalization"`
+	State                   string    `json:"state"`
+	Chapters                []Chapter `json:"chapters,omitempty"`
+}
+
+// IsConverting reports whether the project is being converted or is queued for conversion.
+func (p Project) IsConverting() bool {
+	return p.State == ProjectStateConverting || p.State == ProjectStateInQueue
+}
+
+type Chapter struct {
+	ChapterId              string  `json:"chapter_id"`
+	Name                   string  `json:"name"`
+	LastConversionDateUnix int     `json:"last_conversion_date_unix"`
+	ConversionProgress     float64 `json:"conversion_progress"`
+	CanBeDownloaded        bool    `json:"can_be_downloaded"`
+	State                  string  `json:"state"`
+}
+
+type GetChaptersResponse struct {
+	Chapters []Chapter `json:"chapters"`
+}
+
+type GetChapterSnapshotsResponse struct {
+	Snapshots []ChapterSnapshot `json:"snapshots"`
+}
+
+// ChapterSnapshot is the audio rendered by one conversion of a chapter.
+type ChapterSnapshot struct {
+	ChapterSnapshotId string `json:"chapter_snapshot_id"`
+	ProjectId         string `json:"project_id"`
+	ChapterId         string `json:"chapter_id"`
+	CreatedAtUnix     int    `json:"created_at_unix"`
+	Name              string `json:"name"`
+}
+
+type GetProjectSnapshotsResponse struct {
+	Snapshots []ProjectSnapshot `json:"snapshots"`
+}
+
+// ProjectSnapshot is the audio rendered by one conversion of a whole project.
+type ProjectSnapshot struct {
+	ProjectSnapshotId string `json:"project_snapshot_id"`
+	ProjectId         string `json:"project_id"`
+	CreatedAtUnix     int    `json:"created_at_unix"`
+	Name              string `json:"name"`
+}
+
+// CreateProjectRequest contains the information of a project to be created with CreateProject.
+//
+// It is sent as a multipart form. The content of the project is taken either from the document at
+// FromDocument (a path to an epub, pdf or txt file) or from the web page at FromURL.
+type CreateProjectRequest struct {
+	Name                    string
+	DefaultTitleVoiceId     string
+	DefaultParagraphVoiceId string
+	DefaultModelId          string
+	FromURL                 string
+	FromDocument            string
+	QualityPreset           string
+	Title                   string
+	Author                  string
+	IsbnNumber              string
+	VolumeNormalization     bool
+}
+
+func (r *CreateProjectRequest) buildRequestBody() (*bytes.Buffer, string, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	buildFailed := func(err error) (*bytes.Buffer, string, error) {
+		return nil, "", fmt.Errorf("failed to build request body: %w", err)
+	}
+
+	fields := []struct{ name, value string }{
+		{"name", r.Name},
+		{"default_title_voice_id", r.DefaultTitleVoiceId},
+		{"default_paragraph_voice_id", r.DefaultParagraphVoiceId},
+		{"default_model_id", r.DefaultModelId},
+		{"from_url", r.FromURL},
+		{"quality_preset", r.QualityPreset},
+		{"title", r.Title},
+		{"author", r.Author},
+		{"isbn_number", r.IsbnNumber},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := w.WriteField(f.name, f.value); err != nil {
+			return buildFailed(err)
+		}
+	}
+	if err := w.WriteField("volume_normalization", strconv.FormatBool(r.VolumeNormalization)); err != nil {
+		return buildFailed(err)
+	}
+
+	if r.FromDocument != "" {
+		if err := writeFormFile(w, "from_document", r.FromDocument, ""); err != nil {
+			return buildFailed(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
 		return buildFailed(err)
 	}
 
@@ -43,6 +43,14 @@ var testRespBodies = map[string][]byte{
 	}
 ]`),
 
+	"TestGetVoices-Multiple": []byte(`{
+  "voices": [
+    {"voice_id": "id1", "name": "Rachel", "category": "premade"},
+    {"voice_id": "id2", "name": "My Clone", "category": "cloned"},
+    {"voice_id": "id3", "name": "Adam", "category": "premade"}
+  ]
+}`),
+
 	"TestGetVoices": []byte(`{
   "voices": [
     {
@@ -358,5 +366,251 @@ var testRespBodies = map[string][]byte{
   "is_onboarding_complete": false,
   "xi_api_key": "string",
   "can_use_delayed_payment_methods": true
+}`),
+	"TestGetProjects": []byte(`{
+  "projects": [
+    {
+      "project_id": "TestProjectID",
+      "name": "TestProject",
+      "create_date_unix": 1714204800,
+      "default_title_voice_id": "TestVoiceID",
+      "default_paragraph_voice_id": "TestVoiceID",
+      "default_model_id": "eleven_multilingual_v2",
+      "last_conversion_date_unix": 0,
+      "can_be_downloaded": false,
+      "title": "Test Title",
+      "author": "Test Author",
+      "isbn_number": "",
+      "volume_normalization": true,
+      "state": "default"
+    }
+  ]
+}`),
+	"TestGetProject": []byte(`{
+  "project_id": "TestProjectID",
+  "name": "TestProject",
+  "create_date_unix": 1714204800,
+  "default_title_voice_id": "TestVoiceID",
+  "default_paragraph_voice_id": "TestVoiceID",
+  "default_model_id": "eleven_multilingual_v2",
+  "last_conversion_date_unix": 1714208400,
+  "can_be_downloaded": false,
+  "title": "Test Title",
+  "author": "Test Author",
+  "isbn_number": "",
+  "volume_normalization": true,
+  "state": "converting",
+  "chapters": [
+    {
+      "chapter_id": "TestChapterID",
+      "name": "Chapter 1",
+      "last_conversion_date_unix": 1714208400,
+      "conversion_progress": 0.5,
+      "can_be_downloaded": false,
+      "state": "converting"
+    }
+  ]
+}`),
+	"TestGetProjectChapters": []byte(`{
+  "chapters": [
+    {
+      "chapter_id": "TestChapterID",
+      "name": "Chapter 1",
+      "last_conversion_date_unix": 1714208400,
+      "conversion_progress": 1,
+      "can_be_downloaded": true,
+      "state": "default"
+    }
+  ]
+}`),
+	"TestGetChapterSnapshots": []byte(`{
+  "snapshots": [
+    {
+      "chapter_snapshot_id": "TestChapterSnapshotID",
+      "project_id": "TestProjectID",
+      "chapter_id": "TestChapterID",
+      "created_at_unix": 1714208400,
+      "name": "Chapter 1"
+    }
+  ]
+}`),
+	"TestGetProjectSnapshots": []byte(`{
+  "snapshots": [
+    {
+      "project_snapshot_id": "TestSnapshotID",
+      "project_id": "TestProjectID",
+      "created_at_unix": 1714208400,
+      "name": "TestProject"
+    }
+  ]
+}`),
+	"TestDownloadProjectSnapshot": []byte("testprojectsnapshotaudiobytes"),
+	"TestSubscriptionLimits": []byte(`{
+  "tier": "creator",
+  "character_count": 48213,
+  "character_limit": 100000,
+  "can_extend_character_limit": true,
+  "allowed_to_extend_character_limit": false,
+  "next_character_count_reset_unix": 1717200000,
+  "voice_slots_used": 28,
+  "voice_limit": 30,
+  "max_voice_add_edits": 95,
+  "voice_add_edit_counter": 12,
+  "professional_voice_limit": 1,
+  "can_extend_voice_limit": false,
+  "can_use_instant_voice_cloning": true,
+  "can_use_professional_voice_cloning": true,
+  "currency": "usd",
+  "status": "active",
+  "billing_period": "monthly_period",
+  "character_refresh_period": "monthly_period"
+}`),
+	"TestGetCharacterUsage": []byte(`{
+  "time": [1714521600000, 1714608000000],
+  "usage": {
+    "VoiceID1": [1000, 500],
+    "VoiceID2": [0, 250]
+  }
+}`),
+	"TestValidationErrorFields": []byte(`{
+  "detail": [
+    {
+      "loc": [
+        "body",
+        "text"
+      ],
+      "msg": "field required",
+      "type": "value_error.missing"
+    },
+    {
+      "loc": [
+        "body",
+        "voice_settings",
+        "stability"
+      ],
+      "msg": "ensure this value is less than or equal to 1.0",
+      "type": "value_error.number.not_le",
+      "ctx": {
+        "limit_value": 1.0
+      }
+    },
+    {
+      "loc": [
+        "query",
+        "optimize_streaming_latency"
+      ],
+      "msg": "value is not a valid integer",
+      "type": "type_error.integer"
+    }
+  ]
+}`),
+	"TestErrQuotaExceeded": []byte(`{
+  "detail": {
+    "status": "quota_exceeded",
+    "message": "This request exceeds your quota of 10000. You have 12 credits remaining, while 140 credits are required for this request."
+  }
+}`),
+	"TestGetVoiceFineTuningStatus": []byte(`{
+  "voice_id": "TestVoiceID",
+  "name": "Narrator",
+  "category": "professional",
+  "fine_tuning": {
+    "is_allowed_to_fine_tune": true,
+    "language": "en",
+    "finetuning_state": "fine_tuned",
+    "state": {
+      "eleven_multilingual_v2": "fine_tuned",
+      "eleven_turbo_v2_5": "fine_tuning"
+    },
+    "progress": {
+      "eleven_multilingual_v2": 1,
+      "eleven_turbo_v2_5": 0.42
+    },
+    "message": {
+      "eleven_turbo_v2_5": "Training in progress"
+    },
+    "verification_failures": [],
+    "verification_attempts_count": 1,
+    "manual_verification_requested": false,
+    "verification_attempts": [
+      {
+        "text": "The quick brown fox jumps over the lazy dog.",
+        "date_unix": 1714204800,
+        "accepted": true,
+        "similarity": 0.93,
+        "levenshtein_distance": 2,
+        "recording": {
+          "recording_id": "TestRecordingID",
+          "mime_type": "audio/mpeg",
+          "size_bytes": 102400,
+          "upload_date_unix": 1714204790,
+          "transcription": "The quick brown fox jumps over the lazy dog."
+        }
+      }
+    ],
+    "slice_ids": ["slice1", "slice2"]
+  }
+}`),
+	"TestGetSharedVoices": []byte(`{
+  "voices": [
+    {
+      "public_owner_id": "owner1",
+      "voice_id": "voice1",
+      "name": "Aria",
+      "accent": "american",
+      "gender": "female",
+      "age": "young",
+      "use_case": "narrative_story",
+      "category": "professional",
+      "language": "en",
+      "preview_url": "https://example.com/voice1.mp3",
+      "cloned_by_count": 1200,
+      "free_users_allowed": true
+    },
+    {
+      "public_owner_id": "owner2",
+      "voice_id": "voice2",
+      "name": "Hans",
+      "category": "high_quality",
+      "language": "de"
+    },
+    {
+      "public_owner_id": "owner3",
+      "voice_id": "voice3",
+      "name": "Roger",
+      "category": "high_quality",
+      "language": "en"
+    },
+    {
+      "public_owner_id": "owner4",
+      "voice_id": "voice4",
+      "name": "Greta",
+      "category": "professional",
+      "language": "de"
+    }
+  ],
+  "has_more": true,
+  "last_sort_id": "voice4"
+}`),
+	"TestGetVoiceSamples": []byte(`{
+  "voice_id": "TestVoiceID",
+  "name": "Narrator",
+  "category": "cloned",
+  "samples": [
+    {
+      "sample_id": "sample1",
+      "file_name": "intro.mp3",
+      "mime_type": "audio/mpeg",
+      "size_bytes": 482133,
+      "hash": "5d41402abc4b2a76b9719d911017c592"
+    },
+    {
+      "sample_id": "sample2",
+      "file_name": "chapter 1.wav",
+      "mime_type": "audio/wav",
+      "size_bytes": 1764044,
+      "hash": "7d793037a0760186574b0282f2f435e7"
+    }
+  ]
 }`),
 }
@@ -3,21 +3,65 @@
 
 package elevenlabs
 
-import "io"
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// With calls the With method on the default client.
+func With(opts ...Option) *Client {
+	return getDefaultClient().With(opts...)
+}
 
 // TextToSpeech calls the TextToSpeech method on the default client.
 func TextToSpeech(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) ([]byte, error) {
 	return getDefaultClient().TextToSpeech(voiceID, ttsReq, queries...)
 }
 
+// TextToSpeechWithContentType calls the TextToSpeechWithContentType method on the default client.
+func TextToSpeechWithContentType(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) ([]byte, string, error) {
+	return getDefaultClient().TextToSpeechWithContentType(voiceID, ttsReq, queries...)
+}
+
+// BuildTextToSpeechRequest calls the BuildTextToSpeechRequest method on the default client.
+func BuildTextToSpeechRequest(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (*http.Request, error) {
+	return getDefaultClient().BuildTextToSpeechRequest(voiceID, ttsReq, queries...)
+}
+
+// TextToSpeechLong calls the TextToSpeechLong method on the default client.
+func TextToSpeechLong(voiceID string, text string, ttsReq TextToSpeechRequest, maxChars int, queries ...QueryFunc) ([]byte, error) {
+	return getDefaultClient().TextToSpeechLong(voiceID, text, ttsReq, maxChars, queries...)
+}
+
+// TextToSpeechBatch calls the TextToSpeechBatch method on the default client.
+func TextToSpeechBatch(voiceID string, texts []string, ttsReq TextToSpeechRequest, concurrency int, queries ...QueryFunc) ([][]byte, error) {
+	return getDefaultClient().TextToSpeechBatch(voiceID, texts, ttsReq, concurrency, queries...)
+}
+
 // TextToSpeechStream calls the TextToSpeechStream method on the default client.
 func TextToSpeechStream(streamWriter io.Writer, voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) error {
 	return getDefaultClient().TextToSpeechStream(streamWriter, voiceID, ttsReq, queries...)
 }
 
+// BuildTextToSpeechStreamRequest calls the BuildTextToSpeechStreamRequest method on the default client.
+func BuildTextToSpeechStreamRequest(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (*http.Request, error) {
+	return getDefaultClient().BuildTextToSpeechStreamRequest(voiceID, ttsReq, queries...)
+}
+
+// TextToSpeechPipe calls the TextToSpeechPipe method on the default client.
+func TextToSpeechPipe(voiceID string, ttsReq TextToSpeechRequest, queries ...QueryFunc) (io.ReadCloser, error) {
+	return getDefaultClient().TextToSpeechPipe(voiceID, ttsReq, queries...)
+}
+
 // TextToSpeechInputStream calls the TextToSpeechInputStream method on the default client.
-func TextToSpeechInputStream(textReader chan string, responseChan chan StreamingOutputResponse, AudioResponsePipe io.Writer,voiceID string, modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) error {
-	return getDefaultClient().TextToSpeechInputStream(textReader, responseChan,AudioResponsePipe, voiceID, modelID, ttsReq, queries...)
+func TextToSpeechInputStream(textReader chan string, responseChan chan StreamingOutputResponse, AudioResponsePipe io.Writer, voiceID string, modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) error {
+	return getDefaultClient().TextToSpeechInputStream(textReader, responseChan, AudioResponsePipe, voiceID, modelID, ttsReq, queries...)
+}
+
+// TextToSpeechInputStreamReader calls the TextToSpeechInputStreamReader method on the default client.
+func TextToSpeechInputStreamReader(r io.Reader, audioOut io.Writer, voiceID, modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) error {
+	return getDefaultClient().TextToSpeechInputStreamReader(r, audioOut, voiceID, modelID, ttsReq, queries...)
 }
 
 // GetModels calls the GetModels method on the default client.
@@ -25,9 +69,49 @@ func GetModels() ([]Model, error) {
 	return getDefaultClient().GetModels()
 }
 
+// BuildGetModelsRequest calls the BuildGetModelsRequest method on the default client.
+func BuildGetModelsRequest() (*http.Request, error) {
+	return getDefaultClient().BuildGetModelsRequest()
+}
+
+// ValidateLanguageForModel calls the ValidateLanguageForModel method on the default client.
+func ValidateLanguageForModel(modelID, langCode string) error {
+	return getDefaultClient().ValidateLanguageForModel(modelID, langCode)
+}
+
+// WillExceedLimit calls the WillExceedLimit method on the default client.
+func WillExceedLimit(modelID, text string) (bool, int, error) {
+	return getDefaultClient().WillExceedLimit(modelID, text)
+}
+
 // GetVoices calls the GetVoices method on the default client.
-func GetVoices() ([]Voice, error) {
-	return getDefaultClient().GetVoices()
+func GetVoices(queries ...QueryFunc) ([]Voice, error) {
+	return getDefaultClient().GetVoices(queries...)
+}
+
+// BuildGetVoicesRequest calls the BuildGetVoicesRequest method on the default client.
+func BuildGetVoicesRequest(queries ...QueryFunc) (*http.Request, error) {
+	return getDefaultClient().BuildGetVoicesRequest(queries...)
+}
+
+// GetVoicesPaged calls the GetVoicesPaged method on the default client.
+func GetVoicesPaged(queries ...QueryFunc) (GetVoicesResponse, NextVoicesPageFunc, error) {
+	return getDefaultClient().GetVoicesPaged(queries...)
+}
+
+// GetVoicesByCategory calls the GetVoicesByCategory method on the default client.
+func GetVoicesByCategory(category string) ([]Voice, error) {
+	return getDefaultClient().GetVoicesByCategory(category)
+}
+
+// GetSharedVoices calls the GetSharedVoices method on the default client.
+func GetSharedVoices(queries ...QueryFunc) (GetSharedVoicesResponse, error) {
+	return getDefaultClient().GetSharedVoices(queries...)
+}
+
+// FindVoiceByName calls the FindVoiceByName method on the default client.
+func FindVoiceByName(name string) (Voice, bool, error) {
+	return getDefaultClient().FindVoiceByName(name)
 }
 
 // GetDefaultVoiceSettings calls the GetDefaultVoiceSettings method on the default client.
@@ -35,6 +119,11 @@ func GetDefaultVoiceSettings() (VoiceSettings, error) {
 	return getDefaultClient().GetDefaultVoiceSettings()
 }
 
+// RefreshDefaultVoiceSettings calls the RefreshDefaultVoiceSettings method on the default client.
+func RefreshDefaultVoiceSettings() (VoiceSettings, error) {
+	return getDefaultClient().RefreshDefaultVoiceSettings()
+}
+
 // GetVoiceSettings calls the GetVoiceSettings method on the default client.
 func GetVoiceSettings(voiceId string) (VoiceSettings, error) {
 	return getDefaultClient().GetVoiceSettings(voiceId)
@@ -45,6 +134,16 @@ func GetVoice(voiceId string, queries ...QueryFunc) (Voice, error) {
 	return getDefaultClient().GetVoice(voiceId, queries...)
 }
 
+// GetVoiceFineTuningStatus calls the GetVoiceFineTuningStatus method on the default client.
+func GetVoiceFineTuningStatus(voiceId string) (FineTuningStatus, error) {
+	return getDefaultClient().GetVoiceFineTuningStatus(voiceId)
+}
+
+// GetVoicePreview calls the GetVoicePreview method on the default client.
+func GetVoicePreview(voiceId string) ([]byte, error) {
+	return getDefaultClient().GetVoicePreview(voiceId)
+}
+
 // DeleteVoice calls the DeleteVoice method on the default client.
 func DeleteVoice(voiceId string) error {
 	return getDefaultClient().DeleteVoice(voiceId)
@@ -55,16 +154,41 @@ func EditVoiceSettings(voiceId string, settings VoiceSettings) error {
 	return getDefaultClient().EditVoiceSettings(voiceId, settings)
 }
 
+// EditVoiceSettingsWithResult calls the EditVoiceSettingsWithResult method on the default client.
+func EditVoiceSettingsWithResult(voiceId string, settings VoiceSettings) (VoiceSettings, error) {
+	return getDefaultClient().EditVoiceSettingsWithResult(voiceId, settings)
+}
+
+// PatchVoiceSettings calls the PatchVoiceSettings method on the default client.
+func PatchVoiceSettings(voiceId string, fn func(*VoiceSettings)) error {
+	return getDefaultClient().PatchVoiceSettings(voiceId, fn)
+}
+
 // AddVoice calls the AddVoice method on the default client.
 func AddVoice(voiceReq AddEditVoiceRequest) (string, error) {
 	return getDefaultClient().AddVoice(voiceReq)
 }
 
+// AddVoiceFull calls the AddVoiceFull method on the default client.
+func AddVoiceFull(voiceReq AddEditVoiceRequest) (AddVoiceResponse, error) {
+	return getDefaultClient().AddVoiceFull(voiceReq)
+}
+
+// AddVoiceIdempotent calls the AddVoiceIdempotent method on the default client.
+func AddVoiceIdempotent(voiceReq AddEditVoiceRequest) (string, error) {
+	return getDefaultClient().AddVoiceIdempotent(voiceReq)
+}
+
 // EditVoice calls the EditVoice method on the default client.
 func EditVoice(voiceId string, voiceReq AddEditVoiceRequest) error {
 	return getDefaultClient().EditVoice(voiceId, voiceReq)
 }
 
+// EditVoiceMetadata calls the EditVoiceMetadata method on the default client.
+func EditVoiceMetadata(voiceId string, name, description string, labels map[string]string) error {
+	return getDefaultClient().EditVoiceMetadata(voiceId, name, description, labels)
+}
+
 // DeleteSample calls the DeleteSample method on the default client.
 func DeleteSample(voiceId, sampleId string) error {
 	return getDefaultClient().DeleteSample(voiceId, sampleId)
@@ -75,31 +199,81 @@ func GetSampleAudio(voiceId, sampleId string) ([]byte, error) {
 	return getDefaultClient().GetSampleAudio(voiceId, sampleId)
 }
 
+// AddHistoryItemAsSample calls the AddHistoryItemAsSample method on the default client.
+func AddHistoryItemAsSample(voiceId, historyItemId string) error {
+	return getDefaultClient().AddHistoryItemAsSample(voiceId, historyItemId)
+}
+
+// GetVoiceSamples calls the GetVoiceSamples method on the default client.
+func GetVoiceSamples(voiceId string) ([]VoiceSample, error) {
+	return getDefaultClient().GetVoiceSamples(voiceId)
+}
+
+// DownloadVoiceSamples calls the DownloadVoiceSamples method on the default client.
+func DownloadVoiceSamples(voiceId string) ([]byte, error) {
+	return getDefaultClient().DownloadVoiceSamples(voiceId)
+}
+
 // GetHistory calls the GetHistory method on the default client.
 func GetHistory(queries ...QueryFunc) (GetHistoryResponse, NextHistoryPageFunc, error) {
 	return getDefaultClient().GetHistory(queries...)
 }
 
+// BuildGetHistoryRequest calls the BuildGetHistoryRequest method on the default client.
+func BuildGetHistoryRequest(queries ...QueryFunc) (*http.Request, error) {
+	return getDefaultClient().BuildGetHistoryRequest(queries...)
+}
+
 // GetHistoryItem calls the GetHistoryItem method on the default client.
 func GetHistoryItem(itemId string) (HistoryItem, error) {
 	return getDefaultClient().GetHistoryItem(itemId)
 }
 
+// GetHistoryItems calls the GetHistoryItems method on the default client.
+func GetHistoryItems(itemIds []string) ([]HistoryItem, error) {
+	return getDefaultClient().GetHistoryItems(itemIds)
+}
+
 // DeleteHistoryItem calls the DeleteHistoryItem method on the default client.
 func DeleteHistoryItem(itemId string) error {
 	return getDefaultClient().DeleteHistoryItem(itemId)
 }
 
+// DeleteHistoryItems calls the DeleteHistoryItems method on the default client.
+func DeleteHistoryItems(itemIds []string) error {
+	return getDefaultClient().DeleteHistoryItems(itemIds)
+}
+
 // GetHistoryItemAudio calls the GetHistoryItemAudio method on the default client.
 func GetHistoryItemAudio(itemId string) ([]byte, error) {
 	return getDefaultClient().GetHistoryItemAudio(itemId)
 }
 
+// StreamHistoryItemAudio calls the StreamHistoryItemAudio method on the default client.
+func StreamHistoryItemAudio(w io.Writer, itemId string) error {
+	return getDefaultClient().StreamHistoryItemAudio(w, itemId)
+}
+
+// HistoryItemAudioReader calls the HistoryItemAudioReader method on the default client.
+func HistoryItemAudioReader(itemId string) (io.ReadCloser, error) {
+	return getDefaultClient().HistoryItemAudioReader(itemId)
+}
+
 // DownloadHistoryAudio calls the DownloadHistoryAudio method on the default client.
 func DownloadHistoryAudio(dlReq DownloadHistoryRequest) ([]byte, error) {
 	return getDefaultClient().DownloadHistoryAudio(dlReq)
 }
 
+// BuildDownloadHistoryAudioRequest calls the BuildDownloadHistoryAudioRequest method on the default client.
+func BuildDownloadHistoryAudioRequest(dlReq DownloadHistoryRequest) (*http.Request, error) {
+	return getDefaultClient().BuildDownloadHistoryAudioRequest(dlReq)
+}
+
+// StreamDownloadHistoryAudio calls the StreamDownloadHistoryAudio method on the default client.
+func StreamDownloadHistoryAudio(w io.Writer, dlReq DownloadHistoryRequest) error {
+	return getDefaultClient().StreamDownloadHistoryAudio(w, dlReq)
+}
+
 // GetSubscription calls the GetSubscription method on the default client.
 func GetSubscription() (Subscription, error) {
 	return getDefaultClient().GetSubscription()
@@ -109,3 +283,63 @@ func GetSubscription() (Subscription, error) {
 func GetUser() (User, error) {
 	return getDefaultClient().GetUser()
 }
+
+// GetCharacterUsage calls the GetCharacterUsage method on the default client.
+func GetCharacterUsage(start, end time.Time, queries ...QueryFunc) (UsageStats, error) {
+	return getDefaultClient().GetCharacterUsage(start, end, queries...)
+}
+
+// GetProjects calls the GetProjects method on the default client.
+func GetProjects() ([]Project, error) {
+	return getDefaultClient().GetProjects()
+}
+
+// CreateProject calls the CreateProject method on the default client.
+func CreateProject(projectReq CreateProjectRequest) (Project, error) {
+	return getDefaultClient().CreateProject(projectReq)
+}
+
+// GetProject calls the GetProject method on the default client.
+func GetProject(projectId string) (Project, error) {
+	return getDefaultClient().GetProject(projectId)
+}
+
+// DeleteProject calls the DeleteProject method on the default client.
+func DeleteProject(projectId string) error {
+	return getDefaultClient().DeleteProject(projectId)
+}
+
+// ConvertProject calls the ConvertProject method on the default client.
+func ConvertProject(projectId string) error {
+	return getDefaultClient().ConvertProject(projectId)
+}
+
+// GetProjectChapters calls the GetProjectChapters method on the default client.
+func GetProjectChapters(projectId string) ([]Chapter, error) {
+	return getDefaultClient().GetProjectChapters(projectId)
+}
+
+// GetChapterSnapshots calls the GetChapterSnapshots method on the default client.
+func GetChapterSnapshots(projectId, chapterId string) ([]ChapterSnapshot, error) {
+	return getDefaultClient().GetChapterSnapshots(projectId, chapterId)
+}
+
+// GetProjectSnapshots calls the GetProjectSnapshots method on the default client.
+func GetProjectSnapshots(projectId string) ([]ProjectSnapshot, error) {
+	return getDefaultClient().GetProjectSnapshots(projectId)
+}
+
+// DownloadProjectSnapshot calls the DownloadProjectSnapshot method on the default client.
+func DownloadProjectSnapshot(projectId, snapshotId string, w io.Writer) error {
+	return getDefaultClient().DownloadProjectSnapshot(projectId, snapshotId, w)
+}
+
+// NewDialog calls the NewDialog method on the default client.
+func NewDialog(modelID string, ttsReq TextToSpeechInputStreamingRequest, queries ...QueryFunc) *Dialog {
+	return getDefaultClient().NewDialog(modelID, ttsReq, queries...)
+}
+
+// LastRequestDump calls the LastRequestDump method on the default client.
+func LastRequestDump() string {
+	return getDefaultClient().LastRequestDump()
+}
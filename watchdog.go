@@ -0,0 +1,150 @@
+package elevenlabs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// watchdog cancels a request when it fires. Unlike a context deadline, it can be stopped while the request is
+// in progress, which lets streams be timed out until their first bytes are received only.
+type watchdog struct {
+	timer *time.Timer
+	fired int32
+}
+
+func newWatchdog(timeout time.Duration, cancel context.CancelFunc) *watchdog {
+	w := &watchdog{}
+	w.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&w.fired, 1)
+		cancel()
+	})
+	return w
+}
+
+func (w *watchdog) stop() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// reset makes the watchdog fire after timeout from now instead.
+func (w *watchdog) reset(timeout time.Duration) {
+	w.timer.Reset(timeout)
+}
+
+// err returns err wrapped with context.DeadlineExceeded if the watchdog fired, or err as is otherwise.
+func (w *watchdog) err(err error) error {
+	if w == nil || err == nil || atomic.LoadInt32(&w.fired) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+}
+
+// watchedWriter stops its watchdog once the first bytes are written to it or, if idleTimeout is positive,
+// resets it to idleTimeout on every write, so that it only fires once no data was written for that long.
+type watchedWriter struct {
+	w           io.Writer
+	wd          *watchdog
+	idleTimeout time.Duration
+	started     bool
+}
+
+func (ww *watchedWriter) Write(p []byte) (int, error) {
+	if ww.idleTimeout > 0 {
+		ww.wd.reset(ww.idleTimeout)
+	} else if !ww.started {
+		ww.wd.stop()
+	}
+	ww.started = true
+	return ww.w.Write(p)
+}
+
+// StreamProgress describes the progress of a stream. It is passed to the callback set with WithStreamProgress.
+type StreamProgress struct {
+	// Bytes is the number of bytes of audio written so far.
+	Bytes int64
+	// Elapsed is the time since the stream was started.
+	Elapsed time.Duration
+	// Done is true for the last call, made once the stream ended, successfully or not.
+	Done bool
+}
+
+// BytesPerSecond returns the average throughput of the stream so far.
+func (p StreamProgress) BytesPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Bytes) / p.Elapsed.Seconds()
+}
+
+// progressWriter calls fn with the progress of the stream written to it every interval, including while nothing
+// is written, and a last time when done is called. If interval is 0 or less, fn is called on every write instead.
+// Calls to fn are serialized. Writes must not be concurrent, which holds for both kinds of streams.
+type progressWriter struct {
+	w        io.Writer
+	fn       func(StreamProgress)
+	interval time.Duration
+	start    time.Time
+	n        int64 // accessed atomically
+
+	mu   sync.Mutex // serializes the calls to fn
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// newProgressWriter returns w wrapped in a progressWriter along with its done function, or w as is and a no-op
+// if fn is nil. The done function must be called once the stream ended.
+func newProgressWriter(w io.Writer, fn func(StreamProgress), interval time.Duration) (io.Writer, func()) {
+	if fn == nil {
+		return w, func() {}
+	}
+	pw := &progressWriter{w: w, fn: fn, interval: interval, start: time.Now(), stop: make(chan struct{})}
+	if interval > 0 {
+		pw.wg.Add(1)
+		go pw.tick()
+	}
+	return pw, pw.done
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	atomic.AddInt64(&pw.n, int64(n))
+	if pw.interval <= 0 {
+		pw.report(false)
+	}
+	return n, err
+}
+
+// tick reports the progress every interval until done is called.
+func (pw *progressWriter) tick() {
+	defer pw.wg.Done()
+	ticker := time.NewTicker(pw.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pw.stop:
+			return
+		case <-ticker.C:
+			pw.report(false)
+		}
+	}
+}
+
+func (pw *progressWriter) report(done bool) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.fn(StreamProgress{Bytes: atomic.LoadInt64(&pw.n), Elapsed: time.Since(pw.start), Done: done})
+}
+
+func (pw *progressWriter) done() {
+	pw.once.Do(func() {
+		close(pw.stop)
+		pw.wg.Wait()
+		pw.report(true)
+	})
+}
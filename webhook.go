@@ -0,0 +1,117 @@
+package elevenlabs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader is the HTTP header in which the API sends the signature of a webhook request, to be
+// passed to VerifyWebhookSignature.
+const WebhookSignatureHeader = "ElevenLabs-Signature"
+
+// WebhookTolerance is how old the timestamp of a webhook signature may be for VerifyWebhookSignature to accept
+// it, so that captured requests can't be replayed later on.
+const WebhookTolerance = 30 * time.Minute
+
+// Types of the events sent to webhooks.
+const (
+	WebhookPostCallTranscription = "post_call_transcription"
+	WebhookPostCallAudio         = "post_call_audio"
+	WebhookCallInitiationFailure = "call_initiation_failure"
+)
+
+// WebhookEvent is the body of a webhook request. Data depends on the Type of the event and can be decoded with
+// DecodeData.
+type WebhookEvent struct {
+	Type           string          `json:"type"`
+	EventTimestamp int64           `json:"event_timestamp"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// DecodeData decodes the data of the event into v, e.g. a *PostCallTranscription for events of type
+// WebhookPostCallTranscription.
+func (e WebhookEvent) DecodeData(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// PostCallTranscription is the data of a webhook event of type WebhookPostCallTranscription, sent once a
+// conversation with an agent has ended and was analyzed.
+type PostCallTranscription struct {
+	AgentID        string                  `json:"agent_id"`
+	ConversationID string                  `json:"conversation_id"`
+	Status         string                  `json:"status"`
+	Transcript     []WebhookTranscriptTurn `json:"transcript"`
+}
+
+// WebhookTranscriptTurn is a turn of the transcript of a PostCallTranscription.
+type WebhookTranscriptTurn struct {
+	Role           string  `json:"role"`
+	Message        string  `json:"message"`
+	TimeInCallSecs float64 `json:"time_in_call_secs"`
+}
+
+// VerifyWebhookSignature verifies that payload, the raw body of a webhook request, was signed by the API with
+// the webhook's secret. The header is the value of the WebhookSignatureHeader header of the request, of the
+// form "t=<timestamp>,v0=<signature>", where the signature is the hex encoded HMAC-SHA256 of the timestamp and
+// the payload joined by a dot.
+//
+// It returns nil if the signature is valid and its timestamp is within WebhookTolerance of the current time,
+// or an error matching ErrInvalidWebhookSignature otherwise.
+func VerifyWebhookSignature(payload []byte, header string, secret string) error {
+	return verifyWebhookSignature(payload, header, secret, time.Now())
+}
+
+// ParseWebhookEvent verifies the signature of a webhook request as VerifyWebhookSignature does and decodes its
+// payload.
+func ParseWebhookEvent(payload []byte, header string, secret string) (WebhookEvent, error) {
+	var event WebhookEvent
+	if err := VerifyWebhookSignature(payload, header, secret); err != nil {
+		return event, err
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+func verifyWebhookSignature(payload []byte, header string, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v0":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed header %q", ErrInvalidWebhookSignature, header)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp %q", ErrInvalidWebhookSignature, timestamp)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > WebhookTolerance || age < -WebhookTolerance {
+		return fmt.Errorf("%w: timestamp %s outside of tolerance", ErrInvalidWebhookSignature, time.Unix(unix, 0).UTC())
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if got, err := hex.DecodeString(signature); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", ErrInvalidWebhookSignature)
+}
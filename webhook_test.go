@@ -0,0 +1,52 @@
+package elevenlabs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"type":"post_call_transcription","event_timestamp":1739537297,"data":{"agent_id":"agent-1","conversation_id":"conv-1","status":"done","transcript":[{"role":"agent","message":"Hello","time_in_call_secs":0}]}}`)
+	signature := "869d9037cccbdb46e505ae9a84d3006a2f8573c17a147a8ef5654f66d099008d"
+	signedAt := time.Unix(1739537297, 0)
+
+	testCases := []struct {
+		name     string
+		payload  []byte
+		header   string
+		secret   string
+		now      time.Time
+		expError bool
+	}{
+		{name: "valid signature", payload: payload, header: "t=1739537297,v0=" + signature, secret: "wsec_test", now: signedAt.Add(time.Minute)},
+		{name: "valid among several signatures", payload: payload, header: "t=1739537297,v0=00ff,v0=" + signature, secret: "wsec_test", now: signedAt},
+		{name: "wrong secret", payload: payload, header: "t=1739537297,v0=" + signature, secret: "wsec_other", now: signedAt, expError: true},
+		{name: "tampered payload", payload: append([]byte(" "), payload...), header: "t=1739537297,v0=" + signature, secret: "wsec_test", now: signedAt, expError: true},
+		{name: "expired timestamp", payload: payload, header: "t=1739537297,v0=" + signature, secret: "wsec_test", now: signedAt.Add(time.Hour), expError: true},
+		{name: "missing signature", payload: payload, header: "t=1739537297", secret: "wsec_test", now: signedAt, expError: true},
+		{name: "malformed timestamp", payload: payload, header: "t=yesterday,v0=" + signature, secret: "wsec_test", now: signedAt, expError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyWebhookSignature(tc.payload, tc.header, tc.secret, tc.now)
+			if tc.expError && !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Errorf("Expected ErrInvalidWebhookSignature, got %v", err)
+			}
+			if !tc.expError && err != nil {
+				t.Errorf("Expected no errors, got error: %q", err)
+			}
+		})
+	}
+}
+
+func TestWebhookEventDecodeData(t *testing.T) {
+	event := WebhookEvent{Data: []byte(`{"agent_id":"agent-1","conversation_id":"conv-1","status":"done","transcript":[{"role":"agent","message":"Hello","time_in_call_secs":1.5}]}`)}
+	var data PostCallTranscription
+	if err := event.DecodeData(&data); err != nil {
+		t.Fatalf("Expected no errors, got error: %q", err)
+	}
+	if data.ConversationID != "conv-1" || len(data.Transcript) != 1 || data.Transcript[0].TimeInCallSecs != 1.5 {
+		t.Errorf("Unexpected decoded data %+v", data)
+	}
+}